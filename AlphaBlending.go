@@ -3,8 +3,9 @@ package kurento
 import "fmt"
 
 type IAlphaBlending interface {
-	SetMaster(source HubPort, zOrder int) error
-	SetPortProperties(relativeX float64, relativeY float64, zOrder int, relativeWidth float64, relativeHeight float64, port HubPort) error
+	IHub
+	SetMaster(source IHubPort, zOrder int) error
+	SetPortProperties(relativeX float64, relativeY float64, zOrder int, relativeWidth float64, relativeHeight float64, port IHubPort) error
 }
 
 // A `Hub` that mixes the :rom:attr:`MediaType.AUDIO` stream of its connected
@@ -30,7 +31,7 @@ func (elem *AlphaBlending) getConstructorParams(from IMediaObject, options map[s
 }
 
 // Sets the source port that will be the master entry to the mixer
-func (elem *AlphaBlending) SetMaster(source HubPort, zOrder int) error {
+func (elem *AlphaBlending) SetMaster(source IHubPort, zOrder int) error {
 	req := elem.getInvokeRequest()
 
 	params := make(map[string]interface{})
@@ -43,13 +44,10 @@ func (elem *AlphaBlending) SetMaster(source HubPort, zOrder int) error {
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -61,7 +59,7 @@ func (elem *AlphaBlending) SetMaster(source HubPort, zOrder int) error {
 }
 
 // Configure the blending mode of one port.
-func (elem *AlphaBlending) SetPortProperties(relativeX float64, relativeY float64, zOrder int, relativeWidth float64, relativeHeight float64, port HubPort) error {
+func (elem *AlphaBlending) SetPortProperties(relativeX float64, relativeY float64, zOrder int, relativeWidth float64, relativeHeight float64, port IHubPort) error {
 	req := elem.getInvokeRequest()
 
 	params := make(map[string]interface{})
@@ -78,13 +76,10 @@ func (elem *AlphaBlending) SetPortProperties(relativeX float64, relativeY float6
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -0,0 +1,159 @@
+package kurento
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Maximum time given to release created objects after a failure.
+const rollbackTimeout = 10 * time.Second
+
+// Builder creates and connects the elements of a pipeline step by step,
+// keeping track of every object it created. As soon as a step fails, or the
+// context is done, the created objects are released in reverse order and the
+// following steps are skipped.
+//
+//	objects, err := pipeline.NewBuilder(ctx).
+//		Create(player, nil).
+//		Create(ep, nil).
+//		Connect(player, ep, "").
+//		Build()
+type Builder struct {
+	ctx      context.Context
+	pipeline *MediaPipeline
+	created  []IMediaObject
+	err      error
+}
+
+// NewBuilder returns a builder creating objects in the pipeline.
+func (elem *MediaPipeline) NewBuilder(ctx context.Context) *Builder {
+	return &Builder{ctx: ctx, pipeline: elem}
+}
+
+// NewBuilder returns a builder that first creates a pipeline on the
+// connection. The pipeline is released too if a later step fails.
+func (c *Connection) NewBuilder(ctx context.Context) *Builder {
+	b := &Builder{ctx: ctx}
+	pipeline, err := c.NewPipeline(ctx)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.pipeline = pipeline
+	b.created = append(b.created, pipeline)
+	return b
+}
+
+// Pipeline returns the pipeline the builder creates objects in.
+func (b *Builder) Pipeline() *MediaPipeline {
+	return b.pipeline
+}
+
+// Create creates "m" in the pipeline.
+func (b *Builder) Create(m IMediaObject, options map[string]interface{}) *Builder {
+	return b.CreateFrom(b.pipeline, m, options)
+}
+
+// CreateFrom creates "m" from "parent", e.g. a HubPort from its Hub.
+func (b *Builder) CreateFrom(parent IMediaObject, m IMediaObject, options map[string]interface{}) *Builder {
+	return b.Do(func(ctx context.Context) error {
+		p := parent.mediaObject()
+		if err := p.checkCreated(false); err != nil {
+			return err
+		}
+		if err := p.createContext(ctx, m, options); err != nil {
+			return err
+		}
+		b.created = append(b.created, m)
+		return nil
+	})
+}
+
+// Connect connects "source" to "sink" for the given media type, all media types
+// if empty.
+func (b *Builder) Connect(source, sink IMediaElement, mediaType MediaType) *Builder {
+	return b.Do(func(ctx context.Context) error {
+		params := make(map[string]interface{})
+		setIfNotEmpty(params, "sink", sink)
+		setIfNotEmpty(params, "mediaType", mediaType)
+		_, err := source.mediaObject().invoke(ctx, "connect", params)
+		return err
+	})
+}
+
+// Track adds an object created outside of the builder, so that it is
+// released on failure.
+func (b *Builder) Track(m IMediaObject) *Builder {
+	if b.err == nil {
+		b.created = append(b.created, m)
+	}
+	return b
+}
+
+// Do runs a custom step.
+func (b *Builder) Do(step func(ctx context.Context) error) *Builder {
+	if b.err != nil {
+		return b
+	}
+	err := b.ctx.Err()
+	if err == nil {
+		err = step(b.ctx)
+	}
+	if err != nil {
+		b.err = err
+		if rerr := b.rollback(); rerr != nil {
+			b.err = errors.Join(err, rerr)
+		}
+	}
+	return b
+}
+
+// Err returns the error of the failed step, if any.
+func (b *Builder) Err() error {
+	return b.err
+}
+
+// Build returns the created objects, in creation order, or the error of the
+// failed step. In that case the objects were already released. If the context
+// was done during the last step, the objects are released and the context
+// error is returned.
+func (b *Builder) Build() ([]IMediaObject, error) {
+	if b.err == nil {
+		if err := b.ctx.Err(); err != nil {
+			b.err = err
+			if rerr := b.rollback(); rerr != nil {
+				b.err = errors.Join(err, rerr)
+			}
+		}
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.created, nil
+}
+
+// Rollback releases the created objects in reverse order, e.g. when a later
+// application step fails. The builder cannot be used afterwards.
+func (b *Builder) Rollback() error {
+	if b.err == nil {
+		b.err = errors.New("kurento: builder rolled back")
+	}
+	return b.rollback()
+}
+
+// Release the created objects in reverse order, even if the builder context is
+// done.
+func (b *Builder) rollback() error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(b.ctx), rollbackTimeout)
+	defer cancel()
+
+	var errs []error
+	for i := len(b.created) - 1; i >= 0; i-- {
+		if err := b.created[i].mediaObject().releaseContext(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	b.created = nil
+	return errors.Join(errs...)
+}
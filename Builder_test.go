@@ -0,0 +1,104 @@
+package kurento
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBuilderPartialFailure(t *testing.T) {
+	k := newFakeKms(t)
+	ctx := context.Background()
+	player, ep := new(WebRtcEndpoint), new(RtpEndpoint)
+	failed := errors.New("step failed")
+
+	b := k.conn.NewBuilder(ctx).
+		Create(player, nil).
+		Create(ep, nil).
+		Connect(player, ep, "")
+	pipeline := b.Pipeline()
+	want := []string{string(ep.Id), string(player.Id), string(pipeline.Id)}
+
+	skipped := new(WebRtcEndpoint)
+	objects, err := b.
+		Do(func(ctx context.Context) error { return failed }).
+		Create(skipped, nil).
+		Build()
+	if objects != nil || !errors.Is(err, failed) {
+		t.Errorf("Build() = %v, %v", objects, err)
+	}
+	if got := k.released(); !reflect.DeepEqual(got, want) {
+		t.Errorf("released %v, want %v", got, want)
+	}
+	if n := len(k.requests("create")); n != 3 || skipped.Id != "" {
+		t.Errorf("%d objects created, skipped step created %q", n, skipped.Id)
+	}
+	if ids := k.ids(); len(ids) != 0 {
+		t.Errorf("objects left %v", ids)
+	}
+}
+
+func TestBuilderCreateFailure(t *testing.T) {
+	k := newFakeKms(t)
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub, port, ep := new(Composite), new(HubPort), new(WebRtcEndpoint)
+
+	// the port cannot be created from a released hub
+	b := pipeline.NewBuilder(ctx).
+		Create(hub, nil).
+		Create(ep, nil).
+		Do(func(ctx context.Context) error {
+			k.mu.Lock()
+			defer k.mu.Unlock()
+			k.releaseLocked(string(hub.Id))
+			return nil
+		}).
+		CreateFrom(hub, port, nil)
+	if !isGone(b.Err()) {
+		t.Errorf("Err() = %v", b.Err())
+	}
+	// the pipeline was not created by the builder
+	if got, want := k.released(), []string{string(ep.Id), string(hub.Id)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("released %v, want %v", got, want)
+	}
+}
+
+func TestBuilderCancel(t *testing.T) {
+	k := newFakeKms(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := new(WebRtcEndpoint), new(WebRtcEndpoint)
+
+	objects, err := pipeline.NewBuilder(ctx).
+		Create(first, nil).
+		Create(second, nil).
+		Do(func(context.Context) error {
+			cancel()
+			return nil
+		}).
+		Build()
+	if objects != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("Build() = %v, %v", objects, err)
+	}
+	if got, want := k.released(), []string{string(second.Id), string(first.Id)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("released %v, want %v", got, want)
+	}
+
+	// steps are skipped once the context is done
+	skipped := new(WebRtcEndpoint)
+	if _, err := pipeline.NewBuilder(ctx).Create(skipped, nil).Build(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Build() error %v", err)
+	}
+	if n := len(k.requests("create")); n != 3 {
+		t.Errorf("%d create requests, want 3", n)
+	}
+}
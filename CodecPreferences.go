@@ -0,0 +1,146 @@
+package kurento
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/metal3d/kurento-go/sdp"
+)
+
+// CodecPreferences orders, or restricts, the codecs of the offers generated
+// and processed by an SdpEndpoint. Codecs are matched by their Name, e.g.
+// "H264", "opus/48000/2", and by the Properties given, compared to the fmtp
+// parameters, e.g. {"packetization-mode": 1}.
+type CodecPreferences struct {
+	Audio []CodecConfiguration
+	Video []CodecConfiguration
+
+	// Remove the codecs that are not listed, for media types with preferences
+	Restrict bool
+}
+
+// NegotiatedCodec is the codec chosen for a media section.
+type NegotiatedCodec struct {
+	Mid   string
+	Media string // "audio" or "video"
+	Codec sdp.Codec
+}
+
+// SetCodecPreferences sets the codecs preferences applied to the offers
+// generated by GenerateOffer, before they are returned, and to the offers given
+// to ProcessOffer, before they are sent to the media server, which then
+// answers with the first supported codec. The media server keeps the offers it
+// generated unchanged. If the preferences cannot be applied to such an offer,
+// GenerateOffer fails in the have-local-offer state of the media server, use
+// ResetNegotiation before generating another one. Use SetVideoFormat and
+// SetAudioFormat to force a single codec instead.
+func (elem *SdpEndpoint) SetCodecPreferences(p CodecPreferences) {
+	n := &elem.negotiation
+	n.mu.Lock()
+	n.codecs = p
+	n.mu.Unlock()
+}
+
+// NegotiatedCodecs returns the first codec of each audio and video section of
+// the last answer, generated by ProcessOffer or given to ProcessAnswer.
+// Rejected sections are skipped.
+func (elem *SdpEndpoint) NegotiatedCodecs() ([]NegotiatedCodec, error) {
+	n := &elem.negotiation
+	n.mu.Lock()
+	answer := n.answer
+	n.mu.Unlock()
+	if answer == "" {
+		return nil, errors.New("kurento: no SDP answer negotiated yet")
+	}
+
+	desc, err := sdp.Parse(answer)
+	if err != nil {
+		return nil, err
+	}
+	ret := []NegotiatedCodec{}
+	for _, m := range desc.Media {
+		if (m.Type != "audio" && m.Type != "video") || m.Port == 0 {
+			continue
+		}
+		codecs := m.Codecs()
+		if len(codecs) == 0 {
+			continue
+		}
+		mid, _ := m.Attribute("mid")
+		ret = append(ret, NegotiatedCodec{Mid: mid, Media: m.Type, Codec: codecs[0]})
+	}
+	return ret, nil
+}
+
+// Apply the codec preferences to an offer.
+func (n *negotiationState) preferCodecs(offer string) (string, error) {
+	n.mu.Lock()
+	p := n.codecs
+	n.mu.Unlock()
+	if len(p.Audio) == 0 && len(p.Video) == 0 {
+		return offer, nil
+	}
+
+	desc, err := sdp.Parse(offer)
+	if err != nil {
+		return "", err
+	}
+	for _, m := range desc.Media {
+		var confs []CodecConfiguration
+		switch m.Type {
+		case "audio":
+			confs = p.Audio
+		case "video":
+			confs = p.Video
+		}
+		if len(confs) == 0 || m.Port == 0 {
+			continue
+		}
+
+		pts := matchCodecs(m.Codecs(), confs)
+		if p.Restrict {
+			if err := m.KeepPayloadTypes(pts...); err != nil {
+				return "", fmt.Errorf("kurento: no %s codec of the offer matches the preferences", m.Type)
+			}
+		}
+		m.PreferPayloadTypes(pts...)
+	}
+	return desc.String(), nil
+}
+
+// Return the payload types matching the configurations, in their order.
+func matchCodecs(codecs []sdp.Codec, confs []CodecConfiguration) []int {
+	pts := []int{}
+	seen := make(map[int]bool)
+	for _, conf := range confs {
+		for _, c := range codecs {
+			if !seen[c.PayloadType] && matchCodec(c, conf) {
+				pts = append(pts, c.PayloadType)
+				seen[c.PayloadType] = true
+			}
+		}
+	}
+	return pts
+}
+
+func matchCodec(c sdp.Codec, conf CodecConfiguration) bool {
+	parts := strings.Split(conf.Name, "/")
+	if !strings.EqualFold(parts[0], c.Name) {
+		return false
+	}
+	if len(parts) > 1 && parts[1] != strconv.Itoa(c.ClockRate) {
+		return false
+	}
+	if len(parts) > 2 && parts[2] != strconv.Itoa(c.Channels) {
+		return false
+	}
+	for key, value := range conf.Properties {
+		v, ok := c.Parameter(key)
+		if !ok || v != fmt.Sprint(value) {
+			return false
+		}
+	}
+	return true
+}
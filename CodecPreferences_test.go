@@ -0,0 +1,154 @@
+package kurento
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/metal3d/kurento-go/sdp"
+)
+
+const codecTestOffer = "v=0\r\n" +
+	"o=- 0 0 IN IP4 127.0.0.1\r\n" +
+	"s=-\r\n" +
+	"t=0 0\r\n" +
+	"m=audio 9 UDP/TLS/RTP/SAVPF 111 0\r\n" +
+	"a=mid:0\r\n" +
+	"a=rtpmap:111 opus/48000/2\r\n" +
+	"m=video 9 UDP/TLS/RTP/SAVPF 96 97 102 103 104\r\n" +
+	"a=mid:1\r\n" +
+	"a=rtpmap:96 VP8/90000\r\n" +
+	"a=rtpmap:97 rtx/90000\r\n" +
+	"a=fmtp:97 apt=96\r\n" +
+	"a=rtpmap:102 H264/90000\r\n" +
+	"a=fmtp:102 level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42e01f\r\n" +
+	"a=rtpmap:103 rtx/90000\r\n" +
+	"a=fmtp:103 apt=102\r\n" +
+	"a=rtpmap:104 H264/90000\r\n" +
+	"a=fmtp:104 packetization-mode=0;profile-level-id=42e01f\r\n" +
+	"m=video 0 UDP/TLS/RTP/SAVPF 96\r\n" +
+	"a=mid:2\r\n" +
+	"a=rtpmap:96 VP8/90000\r\n" +
+	"m=application 9 UDP/DTLS/SCTP webrtc-datachannel\r\n" +
+	"a=mid:3\r\n"
+
+func TestMatchCodecs(t *testing.T) {
+	desc, err := sdp.Parse(codecTestOffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	audio, video := desc.Media[0].Codecs(), desc.Media[1].Codecs()
+	tests := []struct {
+		codecs []sdp.Codec
+		confs  []CodecConfiguration
+		want   []int
+	}{
+		{video, []CodecConfiguration{{Name: "H264"}}, []int{102, 104}},
+		{video, []CodecConfiguration{{Name: "h264/90000"}}, []int{102, 104}},
+		{video, []CodecConfiguration{{Name: "H264/48000"}}, []int{}},
+		{video, []CodecConfiguration{{Name: "H264", Properties: map[string]interface{}{"packetization-mode": 1}}}, []int{102}},
+		{video, []CodecConfiguration{{Name: "H264", Properties: map[string]interface{}{"profile-level-id": "42e01f", "packetization-mode": "0"}}}, []int{104}},
+		{video, []CodecConfiguration{{Name: "H264", Properties: map[string]interface{}{"packetization-mode": 2}}}, []int{}},
+		{video, []CodecConfiguration{{Name: "VP8"}, {Name: "H264"}}, []int{96, 102, 104}},
+		{video, []CodecConfiguration{{Name: "H264", Properties: map[string]interface{}{"packetization-mode": 0}}, {Name: "H264"}}, []int{104, 102}},
+		{video, []CodecConfiguration{{Name: "AV1"}}, []int{}},
+		{audio, []CodecConfiguration{{Name: "opus/48000/2"}}, []int{111}},
+		{audio, []CodecConfiguration{{Name: "opus/48000/1"}}, []int{}},
+		{audio, []CodecConfiguration{{Name: "PCMU/8000/1"}, {Name: "opus"}}, []int{0, 111}},
+	}
+	for _, tt := range tests {
+		if got := matchCodecs(tt.codecs, tt.confs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("matchCodecs(%v) = %v, want %v", tt.confs, got, tt.want)
+		}
+	}
+}
+
+func TestPreferCodecs(t *testing.T) {
+	tests := []struct {
+		name  string
+		prefs CodecPreferences
+		audio []string
+		video []string
+		err   bool
+	}{
+		{"none", CodecPreferences{}, []string{"111", "0"}, []string{"96", "97", "102", "103", "104"}, false},
+		{"prefer H264", CodecPreferences{Video: []CodecConfiguration{{Name: "H264"}}},
+			[]string{"111", "0"}, []string{"102", "104", "103", "96", "97"}, false},
+		{"restrict to VP8", CodecPreferences{Video: []CodecConfiguration{{Name: "VP8"}}, Restrict: true},
+			[]string{"111", "0"}, []string{"96", "97"}, false},
+		{"restrict both", CodecPreferences{
+			Audio:    []CodecConfiguration{{Name: "PCMU"}},
+			Video:    []CodecConfiguration{{Name: "H264", Properties: map[string]interface{}{"packetization-mode": 1}}},
+			Restrict: true,
+		}, []string{"0"}, []string{"102", "103"}, false},
+		{"prefer unknown", CodecPreferences{Video: []CodecConfiguration{{Name: "AV1"}}},
+			[]string{"111", "0"}, []string{"96", "97", "102", "103", "104"}, false},
+		{"restrict to unknown", CodecPreferences{Video: []CodecConfiguration{{Name: "AV1"}}, Restrict: true}, nil, nil, true},
+	}
+	for _, tt := range tests {
+		n := negotiationState{codecs: tt.prefs}
+		offer, err := n.preferCodecs(codecTestOffer)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%s: no error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		desc, err := sdp.Parse(offer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := desc.Media[0].Formats; !reflect.DeepEqual(got, tt.audio) {
+			t.Errorf("%s: audio formats %v, want %v", tt.name, got, tt.audio)
+		}
+		if got := desc.Media[1].Formats; !reflect.DeepEqual(got, tt.video) {
+			t.Errorf("%s: video formats %v, want %v", tt.name, got, tt.video)
+		}
+		// rejected and data sections are left as they are
+		if got := desc.Media[2].Formats; !reflect.DeepEqual(got, []string{"96"}) {
+			t.Errorf("%s: rejected section formats %v", tt.name, got)
+		}
+		if got := desc.Media[3].Formats; !reflect.DeepEqual(got, []string{"webrtc-datachannel"}) {
+			t.Errorf("%s: data section formats %v", tt.name, got)
+		}
+	}
+}
+
+func TestGenerateOfferCodecPreferences(t *testing.T) {
+	k := newFakeKms(t)
+	k.invoke = func(object, operation string, params map[string]interface{}) (interface{}, *Error) {
+		return codecTestOffer, nil
+	}
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := New[*WebRtcEndpoint](ctx, pipeline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the media server generated an offer the preferences cannot apply to
+	ep.SetCodecPreferences(CodecPreferences{Video: []CodecConfiguration{{Name: "AV1"}}, Restrict: true})
+	if offer, err := ep.GenerateOffer(); err == nil || offer != "" {
+		t.Errorf("GenerateOffer() = %q, %v", offer, err)
+	}
+	if n := ep.Negotiation(); n.State != SDPSTATE_HAVE_LOCAL_OFFER || n.LocalDescriptor != codecTestOffer {
+		t.Errorf("negotiation %+v after a failed preference", n)
+	}
+
+	ep.ResetNegotiation()
+	ep.SetCodecPreferences(CodecPreferences{Video: []CodecConfiguration{{Name: "VP8"}}, Restrict: true})
+	offer, err := ep.GenerateOffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := ep.Negotiation(); n.State != SDPSTATE_HAVE_LOCAL_OFFER || n.LocalDescriptor != offer || offer == codecTestOffer {
+		t.Errorf("negotiation %+v, offer %q", n, offer)
+	}
+}
@@ -3,6 +3,8 @@ package kurento
 import "fmt"
 
 type IComposite interface {
+	IHub
+	isComposite()
 }
 
 // A `Hub` that mixes the :rom:attr:`MediaType.AUDIO` stream of its connected
@@ -26,3 +28,6 @@ func (elem *Composite) getConstructorParams(from IMediaObject, options map[strin
 	return ret
 
 }
+
+// Implement IComposite
+func (elem *Composite) isComposite() {}
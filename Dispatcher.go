@@ -3,7 +3,8 @@ package kurento
 import "fmt"
 
 type IDispatcher interface {
-	Connect(source HubPort, sink HubPort) error
+	IHub
+	Connect(source IHubPort, sink IHubPort) error
 }
 
 // A `Hub` that allows routing between arbitrary port pairs
@@ -28,7 +29,7 @@ func (elem *Dispatcher) getConstructorParams(from IMediaObject, options map[stri
 
 // Connects each corresponding :rom:enum:`MediaType` of the given source port with
 // the sink port.
-func (elem *Dispatcher) Connect(source HubPort, sink HubPort) error {
+func (elem *Dispatcher) Connect(source IHubPort, sink IHubPort) error {
 	req := elem.getInvokeRequest()
 
 	params := make(map[string]interface{})
@@ -41,13 +42,10 @@ func (elem *Dispatcher) Connect(source HubPort, sink HubPort) error {
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
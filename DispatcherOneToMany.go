@@ -3,7 +3,8 @@ package kurento
 import "fmt"
 
 type IDispatcherOneToMany interface {
-	SetSource(source HubPort) error
+	IHub
+	SetSource(source IHubPort) error
 	RemoveSource() error
 }
 
@@ -29,7 +30,7 @@ func (elem *DispatcherOneToMany) getConstructorParams(from IMediaObject, options
 
 // Sets the source port that will be connected to the sinks of every `HubPort` of
 // the dispatcher
-func (elem *DispatcherOneToMany) SetSource(source HubPort) error {
+func (elem *DispatcherOneToMany) SetSource(source IHubPort) error {
 	req := elem.getInvokeRequest()
 
 	params := make(map[string]interface{})
@@ -41,13 +42,10 @@ func (elem *DispatcherOneToMany) SetSource(source HubPort) error {
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -66,13 +64,10 @@ func (elem *DispatcherOneToMany) RemoveSource() error {
 		"operation": "removeSource",
 		"object":    elem.Id,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
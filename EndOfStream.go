@@ -1 +1,17 @@
 package kurento
+
+// Event raised when the media of an endpoint ends, e.g. when an upload to an
+// HttpPostEndpoint completes or a PlayerEndpoint reaches the end of its file.
+const EventEndOfStream = "EndOfStream"
+
+// OnEndOfStream calls "cb" when the uploaded stream ends. It returns the
+// subscription handler ID.
+func (elem *HttpPostEndpoint) OnEndOfStream(cb func()) string {
+	return elem.Subscribe(EventEndOfStream, func(map[string]interface{}) { cb() })
+}
+
+// OnEndOfStream calls "cb" when the played stream ends. It returns the
+// subscription handler ID.
+func (elem *PlayerEndpoint) OnEndOfStream(cb func()) string {
+	return elem.Subscribe(EventEndOfStream, func(map[string]interface{}) { cb() })
+}
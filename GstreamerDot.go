@@ -0,0 +1,623 @@
+package kurento
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// GstState is the state of a GStreamer element, as shown in dot graphs.
+type GstState string
+
+// Implement fmt.Stringer interface
+func (t GstState) String() string {
+	return string(t)
+}
+
+const (
+	GSTSTATE_VOID_PENDING GstState = "VOID_PENDING"
+	GSTSTATE_NULL         GstState = "NULL"
+	GSTSTATE_READY        GstState = "READY"
+	GSTSTATE_PAUSED       GstState = "PAUSED"
+	GSTSTATE_PLAYING      GstState = "PLAYING"
+)
+
+// State icons used by gst_debug_bin_to_dot_data.
+var gstStateIcons = map[byte]GstState{
+	'~': GSTSTATE_VOID_PENDING,
+	'0': GSTSTATE_NULL,
+	'-': GSTSTATE_READY,
+	'=': GSTSTATE_PAUSED,
+	'>': GSTSTATE_PLAYING,
+}
+
+// Direction of a GStreamer pad.
+type GstPadDirection string
+
+// Implement fmt.Stringer interface
+func (t GstPadDirection) String() string {
+	return string(t)
+}
+
+const (
+	GSTPADDIRECTION_UNKNOWN GstPadDirection = "UNKNOWN"
+	GSTPADDIRECTION_SRC     GstPadDirection = "SRC"
+	GSTPADDIRECTION_SINK    GstPadDirection = "SINK"
+)
+
+// GstGraph is a parsed GStreamer dot graph, as returned by GetGstreamerDot.
+type GstGraph struct {
+	// Type and name of the top level bin, e.g. "GstPipeline" and "pipeline0"
+	Type string
+	Name string
+
+	State        GstState
+	PendingState GstState
+
+	Elements []*GstElement
+	Links    []GstLink
+}
+
+// GstElement is an element or a bin of a GStreamer graph.
+type GstElement struct {
+	// Node identifier in the dot graph
+	Id string
+
+	// GType and name, e.g. "GstVP8Enc" and "vp8enc0"
+	Type string
+	Name string
+
+	State        GstState
+	PendingState GstState
+
+	// Non default parameters, e.g. "caps" or "parent"
+	Properties map[string]string
+
+	Pads []*GstPad
+
+	// Children of a bin
+	Children []*GstElement
+}
+
+// GstPad is a pad of a GStreamer element.
+type GstPad struct {
+	// Node identifier in the dot graph
+	Id string
+
+	Name      string
+	Direction GstPadDirection
+
+	// Activation mode and flags, e.g. "[>][bfb]"
+	Flags string
+}
+
+// GstLink is a link between two pads, identified by their node identifiers.
+type GstLink struct {
+	From string
+	To   string
+
+	// Negotiated caps, when shown in the graph
+	Caps string
+}
+
+// Walk calls "fn" for every element of the graph, bins children included,
+// until it returns false.
+func (g *GstGraph) Walk(fn func(path string, e *GstElement) bool) {
+	walkGstElements(g.Elements, "", fn)
+}
+
+func walkGstElements(elements []*GstElement, prefix string, fn func(string, *GstElement) bool) bool {
+	for _, e := range elements {
+		path := prefix + e.Name
+		if !fn(path, e) {
+			return false
+		}
+		if !walkGstElements(e.Children, path+"/", fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindByType returns the elements of the given GType, e.g. "GstVP8Enc".
+func (g *GstGraph) FindByType(typeName string) []*GstElement {
+	var found []*GstElement
+	g.Walk(func(_ string, e *GstElement) bool {
+		if e.Type == typeName {
+			found = append(found, e)
+		}
+		return true
+	})
+	return found
+}
+
+// FindByState returns the elements in the given state.
+func (g *GstGraph) FindByState(state GstState) []*GstElement {
+	var found []*GstElement
+	g.Walk(func(_ string, e *GstElement) bool {
+		if e.State == state {
+			found = append(found, e)
+		}
+		return true
+	})
+	return found
+}
+
+// Return the elements by path, with their links by pad path.
+func (g *GstGraph) index() (map[string]*GstElement, map[string]string) {
+	elements := make(map[string]*GstElement)
+	pads := make(map[string]string)
+	g.Walk(func(path string, e *GstElement) bool {
+		elements[path] = e
+		for _, p := range e.Pads {
+			pads[p.Id] = path + ":" + p.Name
+		}
+		return true
+	})
+
+	links := make(map[string]string)
+	for _, l := range g.Links {
+		from, ok := pads[l.From]
+		to, ok2 := pads[l.To]
+		if ok && ok2 {
+			links[from+" -> "+to] = l.Caps
+		}
+	}
+	return elements, links
+}
+
+// GstGraphDiff holds the differences between two dumps of a graph. Elements are
+// identified by their path of names, e.g. "pipeline0/webrtcbin0/vp8enc0", and
+// links by their pads, e.g. "a:src -> b:sink".
+type GstGraphDiff struct {
+	AddedElements   []string
+	RemovedElements []string
+	StateChanges    []GstStateChange
+	AddedLinks      []string
+	RemovedLinks    []string
+}
+
+// GstStateChange is an element whose state changed between two dumps.
+type GstStateChange struct {
+	Path string
+	From GstState
+	To   GstState
+}
+
+// Empty tells if the two graphs have the same elements, states and links.
+func (d *GstGraphDiff) Empty() bool {
+	return len(d.AddedElements)+len(d.RemovedElements)+len(d.StateChanges)+len(d.AddedLinks)+len(d.RemovedLinks) == 0
+}
+
+// DiffGstGraphs compares two dumps of a graph, "a" being the older one.
+func DiffGstGraphs(a, b *GstGraph) *GstGraphDiff {
+	d := new(GstGraphDiff)
+	aElements, aLinks := a.index()
+	bElements, bLinks := b.index()
+
+	for path, be := range bElements {
+		ae, ok := aElements[path]
+		if !ok {
+			d.AddedElements = append(d.AddedElements, path)
+		} else if ae.State != be.State {
+			d.StateChanges = append(d.StateChanges, GstStateChange{path, ae.State, be.State})
+		}
+	}
+	for path := range aElements {
+		if _, ok := bElements[path]; !ok {
+			d.RemovedElements = append(d.RemovedElements, path)
+		}
+	}
+	for link := range bLinks {
+		if _, ok := aLinks[link]; !ok {
+			d.AddedLinks = append(d.AddedLinks, link)
+		}
+	}
+	for link := range aLinks {
+		if _, ok := bLinks[link]; !ok {
+			d.RemovedLinks = append(d.RemovedLinks, link)
+		}
+	}
+
+	sort.Strings(d.AddedElements)
+	sort.Strings(d.RemovedElements)
+	sort.Strings(d.AddedLinks)
+	sort.Strings(d.RemovedLinks)
+	sort.Slice(d.StateChanges, func(i, j int) bool { return d.StateChanges[i].Path < d.StateChanges[j].Path })
+	return d
+}
+
+// ParseGstreamerDot parses the output of GetGstreamerDot.
+func ParseGstreamerDot(dot string) (*GstGraph, error) {
+	p := &dotParser{tokens: tokenizeDot(dot)}
+	root, err := p.parseGraph()
+	if err != nil {
+		return nil, err
+	}
+
+	g := new(GstGraph)
+	lines := splitDotLabel(root.attrs["label"])
+	if len(lines) > 0 {
+		g.Type = strings.Trim(lines[0], "<>")
+	}
+	if len(lines) > 1 {
+		g.Name = lines[1]
+	}
+	if len(lines) > 2 {
+		g.State, g.PendingState, _ = parseGstState(lines[2])
+	}
+
+	for _, sub := range root.subgraphs {
+		if e := sub.element(); e != nil {
+			g.Elements = append(g.Elements, e)
+		}
+	}
+	root.walkEdges(func(e dotEdge) {
+		if e.attrs["style"] == "invis" {
+			return
+		}
+		caps := e.attrs["label"]
+		if caps == "" {
+			caps = e.attrs["taillabel"]
+		}
+		g.Links = append(g.Links, GstLink{
+			From: e.from,
+			To:   e.to,
+			Caps: strings.Join(splitDotLabel(caps), "\n"),
+		})
+	})
+	return g, nil
+}
+
+// GetGstreamerGraph returns the parsed gstreamer graph of the pipeline.
+func (elem *MediaPipeline) GetGstreamerGraph(details GstreamerDotDetails) (*GstGraph, error) {
+	dot, err := elem.GetGstreamerDot(details)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGstreamerDot(dot)
+}
+
+// GetGstreamerGraph returns the parsed gstreamer graph of the element.
+func (elem *MediaElement) GetGstreamerGraph(details GstreamerDotDetails) (*GstGraph, error) {
+	dot, err := elem.GetGstreamerDot(details)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGstreamerDot(dot)
+}
+
+var gstStateRegexp = regexp.MustCompile(`^\[(.)\](?: -> \[(.)\])?$`)
+
+// Parse a state line, "[>]" or "[=] -> [>]".
+func parseGstState(line string) (GstState, GstState, bool) {
+	m := gstStateRegexp.FindStringSubmatch(line)
+	if m == nil {
+		return "", "", false
+	}
+	state, ok := gstStateIcons[m[1][0]]
+	if !ok {
+		return "", "", false
+	}
+	pending := GSTSTATE_VOID_PENDING
+	if m[2] != "" {
+		pending = gstStateIcons[m[2][0]]
+	}
+	return state, pending, true
+}
+
+// Split a label on its "\n" and "\l" line breaks.
+func splitDotLabel(label string) []string {
+	label = strings.ReplaceAll(label, `\l`, `\n`)
+	var lines []string
+	for _, line := range strings.Split(label, `\n`) {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Statements of a graph or subgraph.
+type dotGraph struct {
+	name      string
+	attrs     map[string]string
+	nodes     []dotNode
+	edges     []dotEdge
+	subgraphs []*dotGraph
+}
+
+type dotNode struct {
+	id    string
+	attrs map[string]string
+}
+
+type dotEdge struct {
+	from, to string
+	attrs    map[string]string
+}
+
+func (g *dotGraph) walkEdges(fn func(dotEdge)) {
+	for _, e := range g.edges {
+		fn(e)
+	}
+	for _, sub := range g.subgraphs {
+		sub.walkEdges(fn)
+	}
+}
+
+// Build an element from a cluster subgraph, nil for other subgraphs.
+func (g *dotGraph) element() *GstElement {
+	if !strings.HasPrefix(g.name, "cluster_") || g.attrs["label"] == "" {
+		return nil
+	}
+	e := &GstElement{
+		Id:         strings.TrimPrefix(g.name, "cluster_"),
+		Properties: make(map[string]string),
+	}
+	lines := splitDotLabel(g.attrs["label"])
+	for i, line := range lines {
+		switch {
+		case i == 0:
+			e.Type = line
+		case i == 1:
+			e.Name = line
+		default:
+			if state, pending, ok := parseGstState(line); ok {
+				e.State, e.PendingState = state, pending
+			} else if k, v, ok := strings.Cut(line, "="); ok {
+				e.Properties[k] = v
+			}
+		}
+	}
+
+	for _, n := range g.nodes {
+		e.Pads = append(e.Pads, newGstPad(n, GSTPADDIRECTION_UNKNOWN))
+	}
+	for _, sub := range g.subgraphs {
+		if child := sub.element(); child != nil {
+			e.Children = append(e.Children, child)
+			continue
+		}
+		// pads are grouped in "<element>_sink" and "<element>_src" subgraphs
+		direction := GSTPADDIRECTION_UNKNOWN
+		if strings.HasSuffix(sub.name, "_sink") {
+			direction = GSTPADDIRECTION_SINK
+		} else if strings.HasSuffix(sub.name, "_src") {
+			direction = GSTPADDIRECTION_SRC
+		}
+		for _, n := range sub.nodes {
+			e.Pads = append(e.Pads, newGstPad(n, direction))
+		}
+	}
+	return e
+}
+
+func newGstPad(n dotNode, direction GstPadDirection) *GstPad {
+	pad := &GstPad{Id: n.id, Direction: direction}
+	lines := splitDotLabel(n.attrs["label"])
+	if len(lines) > 0 {
+		pad.Name = lines[0]
+	}
+	if len(lines) > 1 {
+		pad.Flags = lines[1]
+	}
+	if direction == GSTPADDIRECTION_UNKNOWN {
+		// sink pads are blue, source pads are red
+		switch n.attrs["fillcolor"] {
+		case "#aaaaff", "#ddddff":
+			pad.Direction = GSTPADDIRECTION_SINK
+		case "#ffaaaa", "#ffdddd":
+			pad.Direction = GSTPADDIRECTION_SRC
+		}
+	}
+	return pad
+}
+
+// Minimal parser of the dot language, enough for GStreamer dumps.
+type dotParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *dotParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *dotParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *dotParser) expect(t string) error {
+	if got := p.next(); got != t {
+		return fmt.Errorf("kurento: invalid dot graph, expected %q, got %q at token %d", t, got, p.pos-1)
+	}
+	return nil
+}
+
+func (p *dotParser) parseGraph() (*dotGraph, error) {
+	if p.peek() == "strict" {
+		p.next()
+	}
+	if t := p.next(); t != "digraph" && t != "graph" {
+		return nil, fmt.Errorf("kurento: invalid dot graph, expected digraph, got %q", t)
+	}
+	g := &dotGraph{attrs: make(map[string]string)}
+	if p.peek() != "{" {
+		g.name = unquoteDot(p.next())
+	}
+	if err := p.parseBody(g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (p *dotParser) parseBody(g *dotGraph) error {
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		switch t := p.peek(); t {
+		case "":
+			return fmt.Errorf("kurento: invalid dot graph, unexpected end")
+		case "}":
+			p.next()
+			return nil
+		case ";", ",":
+			p.next()
+		case "subgraph":
+			p.next()
+			sub := &dotGraph{attrs: make(map[string]string)}
+			if p.peek() != "{" {
+				sub.name = unquoteDot(p.next())
+			}
+			if err := p.parseBody(sub); err != nil {
+				return err
+			}
+			g.subgraphs = append(g.subgraphs, sub)
+		case "node", "edge":
+			p.next()
+			if _, err := p.parseAttrs(); err != nil {
+				return err
+			}
+		case "graph":
+			p.next()
+			attrs, err := p.parseAttrs()
+			if err != nil {
+				return err
+			}
+			for k, v := range attrs {
+				g.attrs[k] = v
+			}
+		default:
+			if err := p.parseStatement(g); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Parse "id = value", a node or an edge chain.
+func (p *dotParser) parseStatement(g *dotGraph) error {
+	id := unquoteDot(p.next())
+	if p.peek() == "=" {
+		p.next()
+		g.attrs[id] = unquoteDot(p.next())
+		return nil
+	}
+
+	ids := []string{id}
+	for p.peek() == "->" || p.peek() == "--" {
+		p.next()
+		ids = append(ids, unquoteDot(p.next()))
+	}
+	attrs, err := p.parseAttrs()
+	if err != nil {
+		return err
+	}
+	if len(ids) == 1 {
+		g.nodes = append(g.nodes, dotNode{id, attrs})
+		return nil
+	}
+	for i := 0; i < len(ids)-1; i++ {
+		g.edges = append(g.edges, dotEdge{ids[i], ids[i+1], attrs})
+	}
+	return nil
+}
+
+// Parse optional "[a=b, c=d]" lists.
+func (p *dotParser) parseAttrs() (map[string]string, error) {
+	attrs := make(map[string]string)
+	for p.peek() == "[" {
+		p.next()
+		for p.peek() != "]" {
+			if p.peek() == "" {
+				return nil, fmt.Errorf("kurento: invalid dot graph, unterminated attribute list")
+			}
+			if t := p.peek(); t == "," || t == ";" {
+				p.next()
+				continue
+			}
+			key := unquoteDot(p.next())
+			value := "true"
+			if p.peek() == "=" {
+				p.next()
+				value = unquoteDot(p.next())
+			}
+			attrs[key] = value
+		}
+		p.next()
+	}
+	return attrs, nil
+}
+
+// Split a dot document into identifiers, quoted strings and punctuation.
+// Quoted strings keep their quotes so they are never taken for keywords.
+func tokenizeDot(s string) []string {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.HasPrefix(s[i:], "//") || c == '#':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end < 0 {
+				i = len(s)
+			} else {
+				i += end + 4
+			}
+		case strings.HasPrefix(s[i:], "->") || strings.HasPrefix(s[i:], "--"):
+			tokens = append(tokens, s[i:i+2])
+			i += 2
+		case strings.ContainsRune("{}[]=;,", rune(c)):
+			tokens = append(tokens, string(c))
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j > len(s)-1 {
+				j = len(s) - 1
+			}
+			tokens = append(tokens, s[i:j+1])
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && !unicode.IsSpace(rune(s[j])) && !strings.ContainsRune("{}[]=;,\"", rune(s[j])) &&
+				!strings.HasPrefix(s[j:], "->") {
+				j++
+			}
+			if j == i {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
+
+// Remove the quotes of a quoted string, keeping "\n" and "\l" escapes that
+// are line breaks in labels.
+func unquoteDot(t string) string {
+	if len(t) < 2 || t[0] != '"' || t[len(t)-1] != '"' {
+		return t
+	}
+	return strings.ReplaceAll(t[1:len(t)-1], `\"`, `"`)
+}
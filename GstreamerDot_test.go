@@ -0,0 +1,119 @@
+package kurento
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testdata/pipeline.dot is a getGstreamerDot dump of a WebRtcEndpoint
+// connected to a RecorderEndpoint, trimmed to a few pads.
+func loadGstGraph(t *testing.T, edit func(string) string) *GstGraph {
+	t.Helper()
+	data, err := os.ReadFile("testdata/pipeline.dot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dot := string(data)
+	if edit != nil {
+		dot = edit(dot)
+	}
+	g, err := ParseGstreamerDot(dot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestParseGstreamerDot(t *testing.T) {
+	g := loadGstGraph(t, nil)
+
+	if g.Type != "GstPipeline" || g.Name != "pipeline0" || g.State != GSTSTATE_PLAYING {
+		t.Errorf("graph %q %q %q", g.Type, g.Name, g.State)
+	}
+
+	var paths []string
+	g.Walk(func(path string, e *GstElement) bool {
+		paths = append(paths, path)
+		return true
+	})
+	want := []string{"kmsrecorderendpoint0", "kmswebrtcendpoint0", "kmswebrtcendpoint0/vp8enc0"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("elements %v, want %v", paths, want)
+	}
+
+	recorder := g.Elements[0]
+	if recorder.Type != "KmsRecorderEndpoint" || recorder.State != GSTSTATE_PAUSED || recorder.PendingState != GSTSTATE_PLAYING {
+		t.Errorf("recorder %q %q -> %q", recorder.Type, recorder.State, recorder.PendingState)
+	}
+	if recorder.Properties["uri"] != `"file:///tmp/out.webm"` {
+		t.Errorf("recorder uri %q", recorder.Properties["uri"])
+	}
+	if len(recorder.Pads) != 1 || recorder.Pads[0].Name != "video_sink" || recorder.Pads[0].Direction != GSTPADDIRECTION_SINK {
+		t.Errorf("recorder pads %+v", recorder.Pads)
+	}
+
+	encoders := g.FindByType("GstVP8Enc")
+	if len(encoders) != 1 {
+		t.Fatalf("found %d GstVP8Enc", len(encoders))
+	}
+	enc := encoders[0]
+	if enc.Name != "vp8enc0" || enc.Properties["deadline"] != "1" {
+		t.Errorf("encoder %q %v", enc.Name, enc.Properties)
+	}
+	if len(enc.Pads) != 2 || enc.Pads[0].Direction != GSTPADDIRECTION_SINK || enc.Pads[1].Direction != GSTPADDIRECTION_SRC {
+		t.Errorf("encoder pads %+v", enc.Pads)
+	}
+	if enc.Pads[0].Flags != "[>][bfb]" {
+		t.Errorf("encoder sink flags %q", enc.Pads[0].Flags)
+	}
+
+	if len(g.Links) != 1 {
+		t.Fatalf("links %+v", g.Links)
+	}
+	link := g.Links[0]
+	if !strings.HasSuffix(link.From, "video_src_0_0x7f3c4c0b1a20") || !strings.HasSuffix(link.To, "video_sink_0x7f3c4c0b2e40") {
+		t.Errorf("link %s -> %s", link.From, link.To)
+	}
+	if link.Caps != "video/x-vp8\nwidth: 640\nheight: 480" {
+		t.Errorf("link caps %q", link.Caps)
+	}
+
+	if n := len(g.FindByState(GSTSTATE_PLAYING)); n != 2 {
+		t.Errorf("%d elements playing, want 2", n)
+	}
+}
+
+func TestDiffGstGraphs(t *testing.T) {
+	a := loadGstGraph(t, nil)
+	if d := DiffGstGraphs(a, a); !d.Empty() {
+		t.Errorf("diff of a graph with itself %+v", d)
+	}
+
+	// the recorder reached PLAYING, the encoder was removed and so was the link
+	b := loadGstGraph(t, func(dot string) string {
+		dot = strings.Replace(dot, `[=] -> [>]`, `[>]`, 1)
+		start := strings.Index(dot, "    subgraph cluster_vp8enc0")
+		end := strings.Index(dot[start:], "    }\n") + len("    }\n")
+		dot = dot[:start] + dot[start+end:]
+		return dot[:strings.LastIndex(dot, "  kmswebrtcendpoint0_")] + "}\n"
+	})
+
+	d := DiffGstGraphs(a, b)
+	want := &GstGraphDiff{
+		RemovedElements: []string{"kmswebrtcendpoint0/vp8enc0"},
+		StateChanges: []GstStateChange{
+			{"kmsrecorderendpoint0", GSTSTATE_PAUSED, GSTSTATE_PLAYING},
+		},
+		RemovedLinks: []string{"kmswebrtcendpoint0:video_src_0 -> kmsrecorderendpoint0:video_sink"},
+	}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("diff %+v, want %+v", d, want)
+	}
+
+	reverse := DiffGstGraphs(b, a)
+	if !reflect.DeepEqual(reverse.AddedElements, want.RemovedElements) || !reflect.DeepEqual(reverse.AddedLinks, want.RemovedLinks) {
+		t.Errorf("reverse diff %+v", reverse)
+	}
+}
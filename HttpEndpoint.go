@@ -3,6 +3,8 @@ package kurento
 import "fmt"
 
 type IHttpPostEndpoint interface {
+	IHttpEndpoint
+	isHttpPostEndpoint()
 }
 
 // An `HttpPostEndpoint` contains SINK pads for AUDIO and VIDEO, which provide
@@ -30,7 +32,68 @@ func (elem *HttpPostEndpoint) getConstructorParams(from IMediaObject, options ma
 
 }
 
+// Implement IHttpPostEndpoint
+func (elem *HttpPostEndpoint) isHttpPostEndpoint() {}
+
+type IHttpGetEndpoint interface {
+	IHttpEndpoint
+	isHttpGetEndpoint()
+}
+
+// An "HttpGetEndpoint" contains SOURCE pads for AUDIO and VIDEO, delivering media
+// using HTML5 pseudo-streaming mechanism.
+// This type of endpoint provide unidirectional communications. Its
+// `MediaSink` is associated with the HTTP GET method
+type HttpGetEndpoint struct {
+	HttpEndpoint
+}
+
+// Typed constructor options of an HttpGetEndpoint, use Map to pass them to
+// Create.
+type HttpGetEndpointOptions struct {
+	// Raise a MediaSessionTerminated event when the stream ends
+	TerminateOnEOS bool
+
+	// Container of the stream, WEBM if empty
+	MediaProfile MediaProfileSpecType
+
+	// Seconds to wait for a client to reconnect before terminating the session
+	DisconnectionTimeout int
+}
+
+// Map returns the options for Create.
+func (o HttpGetEndpointOptions) Map() map[string]interface{} {
+	ret := map[string]interface{}{
+		"terminateOnEOS": o.TerminateOnEOS,
+	}
+	setIfNotEmpty(ret, "mediaProfile", o.MediaProfile)
+	setIfNotEmpty(ret, "disconnectionTimeout", o.DisconnectionTimeout)
+	return ret
+}
+
+// Return contructor params to be called by "Create".
+func (elem *HttpGetEndpoint) getConstructorParams(from IMediaObject, options map[string]interface{}) map[string]interface{} {
+
+	// Create basic constructor params
+	ret := map[string]interface{}{
+		"mediaPipeline":        fmt.Sprintf("%s", from),
+		"terminateOnEOS":       false,
+		"mediaProfile":         MEDIAPROFILESPECTYPE_WEBM,
+		"disconnectionTimeout": 2,
+	}
+
+	// then merge options
+	mergeOptions(ret, options)
+
+	return ret
+
+}
+
+// Implement IHttpGetEndpoint
+func (elem *HttpGetEndpoint) isHttpGetEndpoint() {}
+
 type IHttpEndpoint interface {
+	ISessionEndpoint
 	GetUrl() (string, error)
 }
 
@@ -56,13 +119,10 @@ func (elem *HttpEndpoint) GetUrl() (string, error) {
 		"operation": "getUrl",
 		"object":    elem.Id,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// // The url as a String
 
@@ -0,0 +1,93 @@
+package kurento
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/metal3d/kurento-go/sdp"
+)
+
+// Type of an ICE candidate, see RFC 8445.
+type CandidateType = sdp.CandidateType
+
+const (
+	CANDIDATETYPE_HOST  CandidateType = sdp.CandidateHost
+	CANDIDATETYPE_SRFLX CandidateType = sdp.CandidateSrflx
+	CANDIDATETYPE_PRFLX CandidateType = sdp.CandidatePrflx
+	CANDIDATETYPE_RELAY CandidateType = sdp.CandidateRelay
+)
+
+// CandidateExtension is a key/value pair at the end of a candidate attribute,
+// e.g. "generation 0".
+type CandidateExtension = sdp.CandidateExtension
+
+// CandidateAttribute is a parsed "candidate" attribute, see RFC 8839. Its
+// String method returns the value with the "candidate:" prefix, as used in
+// IceCandidate.
+type CandidateAttribute = sdp.Candidate
+
+// ParseCandidate parses a candidate attribute, with or without the "a=" and
+// "candidate:" prefixes.
+func ParseCandidate(s string) (*CandidateAttribute, error) {
+	return sdp.ParseCandidate(s)
+}
+
+// Parse parses the candidate attribute. An empty Candidate, sent by browsers
+// at the end of gathering, returns ErrEndOfCandidates.
+func (t IceCandidate) Parse() (*CandidateAttribute, error) {
+	if strings.TrimSpace(t.Candidate) == "" {
+		return nil, ErrEndOfCandidates
+	}
+	return ParseCandidate(t.Candidate)
+}
+
+// ErrEndOfCandidates is returned when parsing an empty candidate.
+var ErrEndOfCandidates = errors.New("kurento: end of candidates")
+
+// NewIceCandidate returns the IceCandidate of a candidate attribute.
+func NewIceCandidate(c CandidateAttribute, sdpMid string, sdpMLineIndex int) IceCandidate {
+	return IceCandidate{
+		Candidate:     c.String(),
+		SdpMid:        sdpMid,
+		SdpMLineIndex: sdpMLineIndex,
+	}
+}
+
+// CandidateFilter tells whether a candidate should be kept.
+type CandidateFilter func(c *CandidateAttribute) bool
+
+// Filters for FilterCandidates and IceCandidate.Allowed.
+var (
+	SkipTCP  CandidateFilter = func(c *CandidateAttribute) bool { return c.Transport != "tcp" }
+	SkipUDP  CandidateFilter = func(c *CandidateAttribute) bool { return c.Transport != "udp" }
+	SkipHost CandidateFilter = func(c *CandidateAttribute) bool { return c.Type != CANDIDATETYPE_HOST }
+	SkipIPv6 CandidateFilter = func(c *CandidateAttribute) bool { return !strings.Contains(c.Address, ":") }
+
+	OnlyRelay CandidateFilter = func(c *CandidateAttribute) bool { return c.Type == CANDIDATETYPE_RELAY }
+)
+
+// Allowed tells whether the candidate passes all the filters, e.g. before
+// calling AddIceCandidate. Candidates that cannot be parsed are not allowed.
+func (t IceCandidate) Allowed(filters ...CandidateFilter) bool {
+	c, err := t.Parse()
+	if err != nil {
+		return false
+	}
+	for _, keep := range filters {
+		if !keep(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterCandidates returns the candidates passing all the filters.
+func FilterCandidates(candidates []IceCandidate, filters ...CandidateFilter) []IceCandidate {
+	ret := []IceCandidate{}
+	for _, c := range candidates {
+		if c.Allowed(filters...) {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
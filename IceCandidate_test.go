@@ -0,0 +1,75 @@
+package kurento
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIceCandidateParse(t *testing.T) {
+	c, err := IceCandidate{Candidate: "candidate:1 1 UDP 2122260223 192.168.1.10 54321 typ host"}.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Transport != "udp" || c.Type != CANDIDATETYPE_HOST || c.Port != 54321 {
+		t.Errorf("parsed %+v", c)
+	}
+
+	for _, empty := range []string{"", " "} {
+		if _, err := (IceCandidate{Candidate: empty}).Parse(); err != ErrEndOfCandidates {
+			t.Errorf("Parse(%q) error %v, want ErrEndOfCandidates", empty, err)
+		}
+	}
+	if _, err := (IceCandidate{Candidate: "candidate:1 1 udp"}).Parse(); err == nil || err == ErrEndOfCandidates {
+		t.Errorf("invalid candidate error %v", err)
+	}
+}
+
+func TestNewIceCandidate(t *testing.T) {
+	c := CandidateAttribute{
+		Foundation: "842163049", Component: 1, Transport: "udp", Priority: 1677729535,
+		Address: "203.0.113.7", Port: 61234, Type: CANDIDATETYPE_SRFLX,
+		RelatedAddress: "192.168.1.10", RelatedPort: 54321,
+	}
+	want := IceCandidate{
+		Candidate:     "candidate:842163049 1 udp 1677729535 203.0.113.7 61234 typ srflx raddr 192.168.1.10 rport 54321",
+		SdpMid:        "0",
+		SdpMLineIndex: 0,
+	}
+	ice := NewIceCandidate(c, "0", 0)
+	if ice != want {
+		t.Errorf("NewIceCandidate = %+v, want %+v", ice, want)
+	}
+	parsed, err := ice.Parse()
+	if err != nil || !reflect.DeepEqual(*parsed, c) {
+		t.Errorf("round trip %+v %v", parsed, err)
+	}
+}
+
+func TestFilterCandidates(t *testing.T) {
+	host := IceCandidate{Candidate: "candidate:1 1 udp 2122260223 192.168.1.10 54321 typ host"}
+	hostTCP := IceCandidate{Candidate: "candidate:2 1 tcp 1518280447 192.168.1.10 9 typ host tcptype active"}
+	hostIPv6 := IceCandidate{Candidate: "candidate:3 1 udp 2122262783 2001:db8::1 54322 typ host"}
+	srflx := IceCandidate{Candidate: "candidate:4 1 udp 1677729535 203.0.113.7 61234 typ srflx raddr 192.168.1.10 rport 54321"}
+	relay := IceCandidate{Candidate: "candidate:5 1 udp 41885439 198.51.100.3 50000 typ relay raddr 203.0.113.7 rport 61234"}
+	invalid := IceCandidate{Candidate: "candidate:6"}
+	all := []IceCandidate{host, hostTCP, hostIPv6, srflx, relay, invalid}
+
+	tests := []struct {
+		name    string
+		filters []CandidateFilter
+		want    []IceCandidate
+	}{
+		{"no filter", nil, []IceCandidate{host, hostTCP, hostIPv6, srflx, relay}},
+		{"skip TCP", []CandidateFilter{SkipTCP}, []IceCandidate{host, hostIPv6, srflx, relay}},
+		{"skip UDP", []CandidateFilter{SkipUDP}, []IceCandidate{hostTCP}},
+		{"skip host", []CandidateFilter{SkipHost}, []IceCandidate{srflx, relay}},
+		{"skip IPv6", []CandidateFilter{SkipIPv6}, []IceCandidate{host, hostTCP, srflx, relay}},
+		{"only relay", []CandidateFilter{OnlyRelay}, []IceCandidate{relay}},
+		{"skip TCP and host", []CandidateFilter{SkipTCP, SkipHost}, []IceCandidate{srflx, relay}},
+	}
+	for _, tt := range tests {
+		if got := FilterCandidates(all, tt.filters...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package kurento
+
+import (
+	"errors"
+
+	"github.com/metal3d/kurento-go/sdp"
+)
+
+// Events of WebRtcEndpoint ICE connectivity.
+const (
+	EventOnIceComponentStateChanged = "OnIceComponentStateChanged"
+)
+
+// IceComponentStateChange is the data of OnIceComponentStateChanged events.
+type IceComponentStateChange struct {
+	StreamId    int
+	ComponentId int
+	State       IceComponentState
+}
+
+// ErrIceRestartUnsupported is returned by RestartIce when the media server
+// generated an offer with the same ICE credentials. The negotiation is reset.
+var ErrIceRestartUnsupported = errors.New("kurento: media server did not generate new ICE credentials")
+
+// OnIceComponentStateChanged calls "cb" each time the state of an ICE
+// component changes, e.g. to FAILED when the remote peer switched networks,
+// then to CONNECTED after a restart. It returns the subscription handler ID.
+func (elem *WebRtcEndpoint) OnIceComponentStateChanged(cb func(IceComponentStateChange)) string {
+	return elem.Subscribe(EventOnIceComponentStateChanged, func(data map[string]interface{}) {
+		change := IceComponentStateChange{}
+		if v, ok := data["streamId"].(float64); ok {
+			change.StreamId = int(v)
+		}
+		if v, ok := data["componentId"].(float64); ok {
+			change.ComponentId = int(v)
+		}
+		if v, ok := data["state"].(string); ok {
+			change.State = IceComponentState(v)
+		}
+		cb(change)
+	})
+}
+
+// RestartIce starts a locally initiated ICE restart of a negotiated endpoint,
+// and returns the new offer for the remote peer. Its answer must be given to
+// ProcessRestartAnswer, or ProcessAnswer which does not gather candidates, or
+// the restart abandoned with CancelRestart. Only one restart or renegotiation
+// can run at a time.
+func (elem *WebRtcEndpoint) RestartIce() (string, error) {
+	n := &elem.negotiation
+	previous, err := n.beginRestart("restartIce")
+	if err != nil {
+		return "", err
+	}
+
+	offer, err := elem.GenerateOffer()
+	if err != nil {
+		n.endRestart()
+		return "", err
+	}
+	if iceUfrag(offer) == iceUfrag(previous.LocalDescriptor) {
+		elem.ResetNegotiation()
+		return "", ErrIceRestartUnsupported
+	}
+	return offer, nil
+}
+
+// ProcessRestartAnswer processes the answer to the offer of RestartIce and
+// gathers new candidates. Remote candidates can be added once it returns.
+func (elem *WebRtcEndpoint) ProcessRestartAnswer(answer string) error {
+	if _, err := elem.ProcessAnswer(answer); err != nil {
+		return err
+	}
+	return elem.GatherCandidates()
+}
+
+// CancelRestart abandons the restart started by RestartIce, e.g. when the
+// remote peer never answered.
+func (elem *WebRtcEndpoint) CancelRestart() {
+	elem.ResetNegotiation()
+}
+
+// ProcessRestartOffer processes a new offer of the remote peer, e.g. with new
+// ICE credentials after a network change, and gathers new candidates. It
+// returns the answer. Remote candidates can be added once it returns.
+func (elem *WebRtcEndpoint) ProcessRestartOffer(offer string) (string, error) {
+	n := &elem.negotiation
+	if _, err := n.beginRestart("processRestartOffer"); err != nil {
+		return "", err
+	}
+	defer n.endRestart()
+
+	answer, err := elem.ProcessOffer(offer)
+	if err != nil {
+		return answer, err
+	}
+	return answer, elem.GatherCandidates()
+}
+
+// Mark a restart pending if a negotiation has completed and none is running,
+// and return the negotiation state.
+func (n *negotiationState) beginRestart(operation string) (SdpNegotiation, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.restart != "" || n.pending != "" {
+		pending := n.restart
+		if pending == "" {
+			pending = n.pending
+		}
+		return SdpNegotiation{}, &SdpStateError{Operation: operation, State: n.current(), Pending: pending}
+	}
+	if n.current() != SDPSTATE_STABLE || n.answer == "" {
+		return SdpNegotiation{}, &SdpStateError{Operation: operation, State: n.current()}
+	}
+	n.restart = operation
+	return SdpNegotiation{
+		State:            n.current(),
+		LocalDescriptor:  n.local,
+		RemoteDescriptor: n.remote,
+	}, nil
+}
+
+func (n *negotiationState) endRestart() {
+	n.mu.Lock()
+	n.restart = ""
+	n.mu.Unlock()
+}
+
+// Return the ICE username fragment of a description, from its first media
+// section or the session.
+func iceUfrag(desc string) string {
+	s, err := sdp.Parse(desc)
+	if err != nil {
+		return ""
+	}
+	for _, m := range s.Media {
+		if ufrag, ok := m.Attribute("ice-ufrag"); ok {
+			return ufrag
+		}
+	}
+	ufrag, _ := s.Attribute("ice-ufrag")
+	return ufrag
+}
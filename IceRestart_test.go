@@ -0,0 +1,80 @@
+package kurento
+
+import "testing"
+
+// Negotiate an offer and its answer, then start a restart.
+func restartingEndpoint(t *testing.T) *SdpEndpoint {
+	t.Helper()
+	elem := new(SdpEndpoint)
+	n := &elem.negotiation
+	if err := n.beginGenerateOffer(); err != nil {
+		t.Fatal(err)
+	}
+	n.endGenerateOffer("offer", true)
+	if err := n.beginProcessAnswer(); err != nil {
+		t.Fatal(err)
+	}
+	n.endProcessAnswer("answer", "", true)
+
+	if _, err := n.beginRestart("restartIce"); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.beginGenerateOffer(); err != nil {
+		t.Fatal(err)
+	}
+	n.endGenerateOffer("restart offer", true)
+	return elem
+}
+
+func TestRestartEndsWithAnswer(t *testing.T) {
+	tests := []struct {
+		name   string
+		finish func(elem *SdpEndpoint)
+		ended  bool
+	}{
+		{"answer processed", func(elem *SdpEndpoint) {
+			elem.negotiation.beginProcessAnswer()
+			elem.negotiation.endProcessAnswer("restart answer", "", true)
+		}, true},
+		{"answer failed", func(elem *SdpEndpoint) {
+			elem.negotiation.beginProcessAnswer()
+			elem.negotiation.endProcessAnswer("restart answer", "", false)
+		}, false},
+		{"reset", func(elem *SdpEndpoint) {
+			elem.ResetNegotiation()
+		}, true},
+	}
+	for _, tt := range tests {
+		elem := restartingEndpoint(t)
+		n := &elem.negotiation
+		tt.finish(elem)
+		if ended := n.restart == ""; ended != tt.ended {
+			t.Errorf("%s: restart ended %v, want %v", tt.name, ended, tt.ended)
+		}
+		if !tt.ended {
+			continue
+		}
+		if _, err := n.beginRestart("restartIce"); err != nil {
+			t.Errorf("%s: cannot restart again: %v", tt.name, err)
+		}
+	}
+}
+
+func TestRestartEndsWithOffer(t *testing.T) {
+	n := new(negotiationState)
+	n.beginProcessOffer("offer")
+	n.endProcessOffer("answer", true)
+
+	for _, ok := range []bool{true, false} {
+		if _, err := n.beginRestart("processRestartOffer"); err != nil {
+			t.Fatal(err)
+		}
+		if err := n.beginProcessOffer("restart offer"); err != nil {
+			t.Fatal(err)
+		}
+		n.endProcessOffer("restart answer", ok)
+		if n.restart != "" {
+			t.Errorf("restart still pending after a processed offer, ok %v", ok)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package kurento
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Transport to reach a TURN server.
+type TurnTransport string
+
+// Implement fmt.Stringer interface
+func (t TurnTransport) String() string {
+	return string(t)
+}
+
+const (
+	TURNTRANSPORT_UDP TurnTransport = "udp"
+	TURNTRANSPORT_TCP TurnTransport = "tcp"
+	TURNTRANSPORT_TLS TurnTransport = "tls"
+)
+
+// Default ports of STUN and TURN servers.
+const (
+	DefaultStunPort    = 3478
+	DefaultTurnPort    = 3478
+	DefaultTurnTLSPort = 5349
+)
+
+// StunServer is the STUN server of a WebRtcEndpoint.
+type StunServer struct {
+	// IP address, or host name resolved by Resolve
+	Host string
+
+	// DefaultStunPort if 0
+	Port int
+}
+
+// TurnServer is the TURN server of a WebRtcEndpoint.
+type TurnServer struct {
+	// IP address, or host name resolved by Resolve
+	Host string
+
+	// DefaultTurnPort, or DefaultTurnTLSPort with TLS, if 0
+	Port int
+
+	// UDP if empty
+	Transport TurnTransport
+
+	Username string
+	Password string
+}
+
+// IceServers are the STUN and TURN servers of a WebRtcEndpoint, set with
+// SetIceServers.
+type IceServers struct {
+	Stun *StunServer
+	Turn *TurnServer
+}
+
+func (s StunServer) port() int {
+	if s.Port == 0 {
+		return DefaultStunPort
+	}
+	return s.Port
+}
+
+// Validate checks that the host is an IP address, as the media server
+// requires, and the port is valid.
+func (s StunServer) Validate() error {
+	if net.ParseIP(s.Host) == nil {
+		return fmt.Errorf("kurento: STUN server address %q is not an IP address", s.Host)
+	}
+	if p := s.port(); p < 1 || p > 65535 {
+		return fmt.Errorf("kurento: invalid STUN server port %d", p)
+	}
+	return nil
+}
+
+func (t TurnServer) port() int {
+	switch {
+	case t.Port != 0:
+		return t.Port
+	case t.Transport == TURNTRANSPORT_TLS:
+		return DefaultTurnTLSPort
+	}
+	return DefaultTurnPort
+}
+
+// Validate checks that the host is an IP address, as the media server
+// requires, and that the other fields can be formatted in a TURN URL. The
+// username may contain ":", as the media server splits the user info at its
+// last ":", but not the password.
+func (t TurnServer) Validate() error {
+	if net.ParseIP(t.Host) == nil {
+		return fmt.Errorf("kurento: TURN server address %q is not an IP address", t.Host)
+	}
+	if p := t.port(); p < 1 || p > 65535 {
+		return fmt.Errorf("kurento: invalid TURN server port %d", p)
+	}
+	switch t.Transport {
+	case "", TURNTRANSPORT_UDP, TURNTRANSPORT_TCP, TURNTRANSPORT_TLS:
+	default:
+		return fmt.Errorf("kurento: invalid TURN transport %q", t.Transport)
+	}
+	if t.Username == "" || t.Password == "" {
+		return errors.New("kurento: TURN server needs a username and a password")
+	}
+	if strings.ContainsAny(t.Username, "@ ") || strings.ContainsAny(t.Password, ":@ ") {
+		return errors.New("kurento: TURN credentials cannot be formatted in a TURN URL")
+	}
+	return nil
+}
+
+// URL returns the TurnUrl of the server:
+// "user:password@address:port[?transport=tcp|tls]". IPv6 addresses are not
+// bracketed, the media server splits the port at the last ":".
+func (t TurnServer) URL() (string, error) {
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+	url := t.Username + ":" + t.Password + "@" + t.Host + ":" + strconv.Itoa(t.port())
+	if t.Transport != "" && t.Transport != TURNTRANSPORT_UDP {
+		url += "?transport=" + string(t.Transport)
+	}
+	return url, nil
+}
+
+// ParseTurnURI returns the TURN server of a "turn:" or "turns:" URI, see
+// RFC 7065, e.g. "turns:turn.example.com:443?transport=tcp" as given to
+// browsers. A "turns:" URI uses TLS, and only TCP as transport.
+func ParseTurnURI(uri, username, password string) (TurnServer, error) {
+	t := TurnServer{Username: username, Password: password}
+	scheme, rest, _ := strings.Cut(uri, ":")
+	rest, query, _ := strings.Cut(rest, "?")
+
+	var transport TurnTransport
+	if query != "" {
+		key, value, _ := strings.Cut(query, "=")
+		if key != "transport" {
+			return t, fmt.Errorf("kurento: invalid TURN URI %q", uri)
+		}
+		transport = TurnTransport(strings.ToLower(value))
+	}
+	switch {
+	case strings.EqualFold(scheme, "turn") && (transport == "" || transport == TURNTRANSPORT_UDP || transport == TURNTRANSPORT_TCP):
+		t.Transport = transport
+	case strings.EqualFold(scheme, "turns") && (transport == "" || transport == TURNTRANSPORT_TCP):
+		t.Transport = TURNTRANSPORT_TLS
+	default:
+		return t, fmt.Errorf("kurento: invalid TURN URI %q", uri)
+	}
+
+	host, port, err := net.SplitHostPort(rest)
+	if err != nil {
+		// no port
+		host = strings.TrimSuffix(strings.TrimPrefix(rest, "["), "]")
+	} else if t.Port, err = strconv.Atoi(port); err != nil {
+		return t, fmt.Errorf("kurento: invalid TURN URI port %q", port)
+	}
+	if host == "" {
+		return t, fmt.Errorf("kurento: TURN URI %q has no host", uri)
+	}
+	t.Host = host
+	return t, nil
+}
+
+// Resolve returns a copy of the servers with host names resolved to IP
+// addresses, IPv4 ones first. A nil resolver uses net.DefaultResolver.
+func (c IceServers) Resolve(ctx context.Context, r *net.Resolver) (IceServers, error) {
+	if r == nil {
+		r = net.DefaultResolver
+	}
+	if c.Stun != nil {
+		stun := *c.Stun
+		host, err := resolveHost(ctx, r, stun.Host)
+		if err != nil {
+			return c, err
+		}
+		stun.Host = host
+		c.Stun = &stun
+	}
+	if c.Turn != nil {
+		turn := *c.Turn
+		host, err := resolveHost(ctx, r, turn.Host)
+		if err != nil {
+			return c, err
+		}
+		turn.Host = host
+		c.Turn = &turn
+	}
+	return c, nil
+}
+
+func resolveHost(ctx context.Context, r *net.Resolver, host string) (string, error) {
+	if net.ParseIP(host) != nil {
+		return host, nil
+	}
+	addrs, err := r.LookupIPAddr(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	for _, a := range addrs {
+		if a.IP.To4() != nil {
+			return a.IP.String(), nil
+		}
+	}
+	if len(addrs) == 0 {
+		return "", fmt.Errorf("kurento: no address for %q", host)
+	}
+	return addrs[0].IP.String(), nil
+}
+
+// SetIceServers resolves and validates the servers, then sets the STUN server
+// address and port and the TURN URL of the endpoint. Nil servers are left
+// unchanged.
+func (elem *WebRtcEndpoint) SetIceServers(ctx context.Context, c IceServers) error {
+	c, err := c.Resolve(ctx, nil)
+	if err != nil {
+		return err
+	}
+	var turnUrl string
+	if c.Stun != nil {
+		if err := c.Stun.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Turn != nil {
+		if turnUrl, err = c.Turn.URL(); err != nil {
+			return err
+		}
+	}
+
+	if c.Stun != nil {
+		if _, err := elem.invoke(ctx, "setStunServerAddress", map[string]interface{}{"stunServerAddress": c.Stun.Host}); err != nil {
+			return err
+		}
+		if _, err := elem.invoke(ctx, "setStunServerPort", map[string]interface{}{"stunServerPort": c.Stun.port()}); err != nil {
+			return err
+		}
+		elem.StunServerAddress = c.Stun.Host
+		elem.StunServerPort = c.Stun.port()
+	}
+	if c.Turn != nil {
+		if _, err := elem.invoke(ctx, "setTurnUrl", map[string]interface{}{"turnUrl": turnUrl}); err != nil {
+			return err
+		}
+		elem.TurnUrl = turnUrl
+	}
+	return nil
+}
+
+// TurnREST mints time-limited TURN credentials from a secret shared with the
+// TURN server, as coturn does with "use-auth-secret", see
+// draft-uberti-behave-turn-rest.
+type TurnREST struct {
+	Secret string
+
+	// Lifetime of the credentials
+	TTL time.Duration
+}
+
+// Credentials returns the username "<expiry>:<user>", with expiry as Unix
+// time, and the password, the base64 HMAC-SHA1 of the username.
+func (r TurnREST) Credentials(user string) (username, password string) {
+	return r.CredentialsAt(user, time.Now().Add(r.TTL))
+}
+
+// CredentialsAt returns credentials expiring at "expires" instead of after TTL.
+func (r TurnREST) CredentialsAt(user string, expires time.Time) (username, password string) {
+	username = strconv.FormatInt(expires.Unix(), 10)
+	if user != "" {
+		username += ":" + user
+	}
+	mac := hmac.New(sha1.New, []byte(r.Secret))
+	mac.Write([]byte(username))
+	return username, base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Server returns a copy of the TURN server with credentials for "user", e.g.
+// one per endpoint.
+func (r TurnREST) Server(t TurnServer, user string) TurnServer {
+	t.Username, t.Password = r.Credentials(user)
+	return t
+}
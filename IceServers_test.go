@@ -0,0 +1,121 @@
+package kurento
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTurnRESTCredentials(t *testing.T) {
+	// Passwords computed with
+	// printf '%s' "$username" | openssl dgst -sha1 -hmac north -binary | base64
+	tests := []struct {
+		user     string
+		username string
+		password string
+	}{
+		{"alice", "1700003600:alice", "wjwSXO2ch1B6VaLTLMy2Avn5O9o="},
+		{"", "1700003600", "xF4I6gruVt/PjGbjajGu2UlidPk="},
+	}
+	r := TurnREST{Secret: "north", TTL: time.Hour}
+	for _, tt := range tests {
+		username, password := r.CredentialsAt(tt.user, time.Unix(1700003600, 0))
+		if username != tt.username || password != tt.password {
+			t.Errorf("CredentialsAt(%q) = %q, %q, want %q, %q", tt.user, username, password, tt.username, tt.password)
+		}
+	}
+
+	before := time.Now().Add(time.Hour).Unix()
+	username, _ := r.Credentials("bob")
+	after := time.Now().Add(time.Hour).Unix()
+	expiry, user, _ := strings.Cut(username, ":")
+	expires, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || expires < before || expires > after || user != "bob" {
+		t.Errorf("username %q does not expire after the TTL", username)
+	}
+}
+
+func TestTurnServerURL(t *testing.T) {
+	tests := []struct {
+		server TurnServer
+		url    string
+	}{
+		{TurnServer{Host: "10.0.0.1", Username: "u", Password: "p"}, "u:p@10.0.0.1:3478"},
+		{TurnServer{Host: "10.0.0.1", Transport: TURNTRANSPORT_UDP, Username: "u", Password: "p"}, "u:p@10.0.0.1:3478"},
+		{TurnServer{Host: "10.0.0.1", Transport: TURNTRANSPORT_TCP, Username: "u", Password: "p"}, "u:p@10.0.0.1:3478?transport=tcp"},
+		{TurnServer{Host: "10.0.0.1", Transport: TURNTRANSPORT_TLS, Username: "u", Password: "p"}, "u:p@10.0.0.1:5349?transport=tls"},
+		{TurnServer{Host: "10.0.0.1", Port: 443, Transport: TURNTRANSPORT_TLS, Username: "u", Password: "p"}, "u:p@10.0.0.1:443?transport=tls"},
+		{TurnServer{Host: "::1", Port: 3479, Username: "1700003600:alice", Password: "p"}, "1700003600:alice:p@::1:3479"},
+		{TurnServer{Host: "2001:db8::7", Transport: TURNTRANSPORT_TLS, Username: "u", Password: "p"}, "u:p@2001:db8::7:5349?transport=tls"},
+	}
+	for _, tt := range tests {
+		url, err := tt.server.URL()
+		if err != nil || url != tt.url {
+			t.Errorf("URL() of %+v = %q, %v, want %q", tt.server, url, err, tt.url)
+		}
+	}
+
+	invalid := []TurnServer{
+		{Host: "turn.example.com", Username: "u", Password: "p"},
+		{Host: "10.0.0.1", Port: 70000, Username: "u", Password: "p"},
+		{Host: "10.0.0.1", Transport: "dtls", Username: "u", Password: "p"},
+		{Host: "10.0.0.1", Username: "u"},
+		{Host: "10.0.0.1", Username: "u@x", Password: "p"},
+		{Host: "10.0.0.1", Username: "u", Password: "p:q"},
+	}
+	for _, server := range invalid {
+		if url, err := server.URL(); err == nil {
+			t.Errorf("URL() of %+v = %q, want an error", server, url)
+		}
+	}
+}
+
+func TestParseTurnURI(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want TurnServer
+		url  string
+	}{
+		{"turn:10.0.0.1", TurnServer{Host: "10.0.0.1"}, "u:p@10.0.0.1:3478"},
+		{"turn:10.0.0.1:3479?transport=udp", TurnServer{Host: "10.0.0.1", Port: 3479, Transport: TURNTRANSPORT_UDP}, "u:p@10.0.0.1:3479"},
+		{"turn:10.0.0.1?transport=TCP", TurnServer{Host: "10.0.0.1", Transport: TURNTRANSPORT_TCP}, "u:p@10.0.0.1:3478?transport=tcp"},
+		{"turns:10.0.0.1", TurnServer{Host: "10.0.0.1", Transport: TURNTRANSPORT_TLS}, "u:p@10.0.0.1:5349?transport=tls"},
+		{"turns:10.0.0.1:443?transport=tcp", TurnServer{Host: "10.0.0.1", Port: 443, Transport: TURNTRANSPORT_TLS}, "u:p@10.0.0.1:443?transport=tls"},
+		{"turns:[::1]:443", TurnServer{Host: "::1", Port: 443, Transport: TURNTRANSPORT_TLS}, "u:p@::1:443?transport=tls"},
+		{"turn:[::1]", TurnServer{Host: "::1"}, "u:p@::1:3478"},
+		{"turns:turn.example.com", TurnServer{Host: "turn.example.com", Transport: TURNTRANSPORT_TLS}, ""},
+	}
+	for _, tt := range tests {
+		got, err := ParseTurnURI(tt.uri, "u", "p")
+		if err != nil {
+			t.Errorf("ParseTurnURI(%q): %v", tt.uri, err)
+			continue
+		}
+		tt.want.Username, tt.want.Password = "u", "p"
+		if got != tt.want {
+			t.Errorf("ParseTurnURI(%q) = %+v, want %+v", tt.uri, got, tt.want)
+		}
+		if tt.url == "" {
+			continue
+		}
+		if url, err := got.URL(); err != nil || url != tt.url {
+			t.Errorf("URL() of %q = %q, %v, want %q", tt.uri, url, err, tt.url)
+		}
+	}
+
+	invalid := []string{
+		"stun:10.0.0.1",
+		"10.0.0.1:3478",
+		"turns:10.0.0.1?transport=udp",
+		"turn:10.0.0.1?transport=tls",
+		"turn:10.0.0.1?proto=tcp",
+		"turn:10.0.0.1:port",
+		"turn:",
+	}
+	for _, uri := range invalid {
+		if s, err := ParseTurnURI(uri, "u", "p"); err == nil {
+			t.Errorf("ParseTurnURI(%q) = %+v, want an error", uri, s)
+		}
+	}
+}
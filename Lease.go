@@ -0,0 +1,233 @@
+package kurento
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Tags set on leased objects.
+const (
+	LeaseOwnerTag   = "kurento-go.lease.owner"
+	LeaseExpiresTag = "kurento-go.lease.expires" // Unix time, in seconds
+)
+
+// MinLeaseTTL is the shortest lease EnableLeases accepts.
+const MinLeaseTTL = 3 * time.Second
+
+// Lease tags the objects created through a connection with an owner and an
+// expiry time, and refreshes the expiry while the application is alive. If
+// the application dies, a Reaper releases the objects once the lease expired.
+type Lease struct {
+	conn  *Connection
+	owner string
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	objects map[ObjectID]IMediaObject
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// EnableLeases makes the pipelines created afterwards through the connection,
+// with Create, NewPipeline or New, leased by "owner" for "ttl". Leases are
+// refreshed every third of "ttl" until Stop is called. Expiry tags have a
+// precision of a second, so "ttl" must be at least MinLeaseTTL. A connection
+// has one lease at a time, the previous one must be stopped first.
+func (c *Connection) EnableLeases(owner string, ttl time.Duration) (*Lease, error) {
+	if ttl < MinLeaseTTL {
+		return nil, fmt.Errorf("kurento: lease ttl %s is shorter than %s", ttl, MinLeaseTTL)
+	}
+	l := &Lease{
+		conn:    c,
+		owner:   owner,
+		ttl:     ttl,
+		objects: make(map[ObjectID]IMediaObject),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lease != nil {
+		return nil, fmt.Errorf("kurento: leases are already enabled for %s", c.lease.owner)
+	}
+	c.lease = l
+
+	go l.heartbeat()
+	return l, nil
+}
+
+// Return the lease of the connection, if enabled.
+func (c *Connection) currentLease() *Lease {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lease
+}
+
+// Add leases an object, e.g. one created before leases were enabled.
+func (l *Lease) Add(ctx context.Context, m IMediaObject) error {
+	if err := l.tag(ctx, m, time.Now().Add(l.ttl)); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.objects[m.mediaObject().Id] = m
+	l.mu.Unlock()
+	return nil
+}
+
+// Remove stops refreshing the lease of an object. Released objects are removed
+// automatically.
+func (l *Lease) Remove(id ObjectID) {
+	l.mu.Lock()
+	delete(l.objects, id)
+	l.mu.Unlock()
+}
+
+// Refresh extends the lease of every leased object.
+func (l *Lease) Refresh(ctx context.Context) error {
+	l.mu.Lock()
+	objects := make([]IMediaObject, 0, len(l.objects))
+	for _, m := range l.objects {
+		objects = append(objects, m)
+	}
+	l.mu.Unlock()
+
+	expires := time.Now().Add(l.ttl)
+	for _, m := range objects {
+		err := l.tag(ctx, m, expires)
+		if isGone(err) {
+			l.Remove(m.mediaObject().Id)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop stops refreshing leases and disables them on the connection. Leased
+// objects keep their tags and will be reaped once expired, unless released.
+func (l *Lease) Stop() {
+	l.conn.mu.Lock()
+	if l.conn.lease == l {
+		l.conn.lease = nil
+	}
+	l.conn.mu.Unlock()
+
+	close(l.stop)
+	<-l.done
+}
+
+// Refresh leases until stopped.
+func (l *Lease) heartbeat() {
+	defer close(l.done)
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), l.ttl/3)
+			if err := l.Refresh(ctx); err != nil && debug {
+				log.Println("Lease refresh error ", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// Set the owner and expiry tags of an object. The expiry tag is removed first
+// so that it is replaced.
+func (l *Lease) tag(ctx context.Context, m IMediaObject, expires time.Time) error {
+	elem := m.mediaObject()
+	if _, err := elem.invoke(ctx, "removeTag", map[string]interface{}{"key": LeaseExpiresTag}); err != nil {
+		return err
+	}
+	return elem.SetTags(ctx, map[string]string{
+		LeaseOwnerTag:   l.owner,
+		LeaseExpiresTag: strconv.FormatInt(expires.Unix(), 10),
+	})
+}
+
+// Reaper releases the pipelines of a server whose lease expired.
+type Reaper struct {
+	conn *Connection
+
+	// Time between two sweeps of Run
+	Interval time.Duration
+
+	// Called for each released pipeline, if set
+	OnReap func(id ObjectID, owner string)
+}
+
+// NewReaper returns a reaper sweeping the server of the connection every
+// "interval".
+func NewReaper(conn *Connection, interval time.Duration) *Reaper {
+	return &Reaper{conn: conn, Interval: interval}
+}
+
+// Sweep releases the pipelines whose lease expired, and returns their IDs.
+// Pipelines without lease tags are left alone.
+func (r *Reaper) Sweep(ctx context.Context) ([]ObjectID, error) {
+	manager, err := r.conn.ServerManager(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pipelines, err := manager.GetPipelines(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	reaped := []ObjectID{}
+	for _, p := range pipelines {
+		elem := p.mediaObject()
+		tags, err := elem.getTagsContext(ctx)
+		if isGone(err) {
+			continue
+		} else if err != nil {
+			return reaped, err
+		}
+
+		tagMap := TagMap(tags)
+		expires, err := strconv.ParseInt(tagMap[LeaseExpiresTag], 10, 64)
+		if err != nil || expires > now {
+			continue
+		}
+		err = elem.releaseContext(ctx)
+		if isGone(err) {
+			continue
+		} else if err != nil {
+			return reaped, err
+		}
+		reaped = append(reaped, elem.Id)
+		if r.OnReap != nil {
+			r.OnReap(elem.Id, tagMap[LeaseOwnerTag])
+		}
+	}
+	return reaped, nil
+}
+
+// Run sweeps the server every Interval until the context is done. It fails
+// at once if Interval is not positive.
+func (r *Reaper) Run(ctx context.Context) error {
+	if r.Interval <= 0 {
+		return fmt.Errorf("kurento: invalid reaper interval %s", r.Interval)
+	}
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.Sweep(ctx); err != nil && debug {
+			log.Println("Reaper sweep error ", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
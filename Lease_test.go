@@ -0,0 +1,71 @@
+package kurento
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLeaseValidation(t *testing.T) {
+	c := new(Connection)
+	for _, ttl := range []time.Duration{0, -time.Second, time.Nanosecond, time.Second} {
+		if l, err := c.EnableLeases("app", ttl); err == nil {
+			l.Stop()
+			t.Errorf("EnableLeases with ttl %s did not fail", ttl)
+		}
+	}
+
+	for _, interval := range []time.Duration{0, -time.Minute} {
+		if err := NewReaper(c, interval).Run(context.Background()); err == nil {
+			t.Errorf("Run with interval %s did not fail", interval)
+		}
+	}
+}
+
+func TestEnableLeasesTwice(t *testing.T) {
+	c := new(Connection)
+	l, err := c.EnableLeases("app", MinLeaseTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other, err := c.EnableLeases("other", MinLeaseTTL); err == nil {
+		other.Stop()
+		t.Error("leases enabled twice")
+	}
+	if c.currentLease() != l {
+		t.Error("the first lease was replaced")
+	}
+	l.Stop()
+
+	l, err = c.EnableLeases("other", MinLeaseTTL)
+	if err != nil {
+		t.Fatalf("EnableLeases after Stop: %v", err)
+	}
+	l.Stop()
+}
+
+func TestLeaseAddFailure(t *testing.T) {
+	k := newFakeKms(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	k.invoke = func(object, operation string, params map[string]interface{}) (interface{}, *Error) {
+		// the context is done while the pipeline is tagged
+		cancel()
+		return nil, &Error{Code: 40001, Message: "Unexpected error"}
+	}
+	l, err := k.conn.EnableLeases("app", MinLeaseTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Stop()
+
+	if _, err := k.conn.NewPipeline(ctx); err == nil {
+		t.Fatal("pipeline created without lease")
+	}
+	if ids := k.ids(); len(ids) != 0 {
+		t.Errorf("objects left %v", ids)
+	}
+	if released := k.released(); len(released) != 1 {
+		t.Errorf("released %v", released)
+	}
+}
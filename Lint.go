@@ -0,0 +1,195 @@
+package kurento
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Kind of problem found by Lint.
+type LintCode string
+
+// Implement fmt.Stringer interface
+func (t LintCode) String() string {
+	return string(t)
+}
+
+const (
+	// An element that consumes media has no source
+	LINTCODE_UNCONNECTED_SINK LintCode = "UNCONNECTED_SINK"
+	// Two audio/video elements are connected for one media type only
+	LINTCODE_MISSING_MEDIA LintCode = "MISSING_MEDIA"
+	// Media loops through several elements
+	LINTCODE_CYCLE LintCode = "CYCLE"
+	// The Hub of a HubPort is not in the pipeline
+	LINTCODE_ORPHAN_HUBPORT LintCode = "ORPHAN_HUBPORT"
+)
+
+// Severity of a lint finding.
+type LintSeverity string
+
+// Implement fmt.Stringer interface
+func (t LintSeverity) String() string {
+	return string(t)
+}
+
+const (
+	LINTSEVERITY_ERROR   LintSeverity = "ERROR"
+	LINTSEVERITY_WARNING LintSeverity = "WARNING"
+)
+
+// LintFinding is a problem found in a pipeline topology.
+type LintFinding struct {
+	Code     LintCode     `json:"code"`
+	Severity LintSeverity `json:"severity"`
+	Object   ObjectID     `json:"object"`
+
+	// Other object involved, e.g. the sink of a connection
+	Peer ObjectID `json:"peer,omitempty"`
+
+	// Media type involved, if any
+	Media MediaType `json:"media,omitempty"`
+
+	Message string `json:"message"`
+}
+
+// Lint queries the topology of the pipeline and reports its problems.
+func Lint(ctx context.Context, pipeline *MediaPipeline) ([]LintFinding, error) {
+	t, err := pipeline.Topology(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return LintTopology(t), nil
+}
+
+// LintTopology reports the problems of a topology: sinks without source,
+// audio/video elements connected for one media type only, cycles and hub ports
+// whose hub is gone. An element connected to itself, a common loopback, and
+// two elements connected to each other, e.g. the endpoints of a one-to-one call
+// or a WebRtcEndpoint and its HubPort, are not cycles.
+func LintTopology(t *Topology) []LintFinding {
+	findings := []LintFinding{}
+
+	nodes := make(map[ObjectID]TopologyNode)
+	for _, n := range t.Nodes {
+		nodes[n.Id] = n
+	}
+	sources := make(map[ObjectID]bool)
+	pairs := make(map[[2]ObjectID]map[MediaType]bool)
+	var pairOrder [][2]ObjectID
+	for _, e := range t.Edges {
+		sources[e.Sink] = true
+		pair := [2]ObjectID{e.Source, e.Sink}
+		if pairs[pair] == nil {
+			pairs[pair] = make(map[MediaType]bool)
+			pairOrder = append(pairOrder, pair)
+		}
+		pairs[pair][e.Type] = true
+	}
+
+	for _, n := range t.Nodes {
+		if info, _ := registeredInfo(n.Type); info.sink && !sources[n.Id] {
+			findings = append(findings, LintFinding{
+				Code:     LINTCODE_UNCONNECTED_SINK,
+				Severity: LINTSEVERITY_ERROR,
+				Object:   n.Id,
+				Message:  fmt.Sprintf("%s %s has no source", n.Type, n.Id),
+			})
+		}
+		if n.Type == "HubPort" && n.Hub != "" && !isHubType(nodes[n.Hub].Type) {
+			findings = append(findings, LintFinding{
+				Code:     LINTCODE_ORPHAN_HUBPORT,
+				Severity: LINTSEVERITY_ERROR,
+				Object:   n.Id,
+				Peer:     n.Hub,
+				Message:  fmt.Sprintf("hub %s of HubPort %s is not in the pipeline", n.Hub, n.Id),
+			})
+		}
+	}
+
+	for _, pair := range pairOrder {
+		source, _ := registeredInfo(nodes[pair[0]].Type)
+		sink, _ := registeredInfo(nodes[pair[1]].Type)
+		if !source.audioVideo || !sink.audioVideo {
+			continue
+		}
+		types := pairs[pair]
+		for _, m := range [][2]MediaType{{MEDIATYPE_AUDIO, MEDIATYPE_VIDEO}, {MEDIATYPE_VIDEO, MEDIATYPE_AUDIO}} {
+			if types[m[0]] && !types[m[1]] {
+				findings = append(findings, LintFinding{
+					Code:     LINTCODE_MISSING_MEDIA,
+					Severity: LINTSEVERITY_WARNING,
+					Object:   pair[0],
+					Peer:     pair[1],
+					Media:    m[1],
+					Message:  fmt.Sprintf("%s is connected to %s for %s but not %s", pair[0], pair[1], m[0], m[1]),
+				})
+			}
+		}
+	}
+
+	for _, cycle := range topologyCycles(t) {
+		path := make([]string, len(cycle))
+		for i, id := range cycle {
+			path[i] = string(id)
+		}
+		findings = append(findings, LintFinding{
+			Code:     LINTCODE_CYCLE,
+			Severity: LINTSEVERITY_ERROR,
+			Object:   cycle[0],
+			Peer:     cycle[len(cycle)-1],
+			Message:  "media loops through " + strings.Join(append(path, path[0]), " -> "),
+		})
+	}
+	return findings
+}
+
+// Return the elementary cycles of 3 elements or more of the graph, each one
+// starting from its smallest ID.
+func topologyCycles(t *Topology) [][]ObjectID {
+	next := make(map[ObjectID][]ObjectID)
+	for _, e := range t.Edges {
+		if e.Source != e.Sink && !containsID(next[e.Source], e.Sink) {
+			next[e.Source] = append(next[e.Source], e.Sink)
+		}
+	}
+	var starts []ObjectID
+	for id := range next {
+		starts = append(starts, id)
+	}
+	sort.Slice(starts, func(i, j int) bool { return starts[i] < starts[j] })
+
+	// only look for cycles through nodes greater than the start node, so each
+	// cycle is found once
+	var cycles [][]ObjectID
+	for _, start := range starts {
+		var path []ObjectID
+		var visit func(id ObjectID)
+		visit = func(id ObjectID) {
+			path = append(path, id)
+			for _, n := range next[id] {
+				if n == start {
+					// self loops are skipped above, and mutual connections here
+					if len(path) > 2 {
+						cycles = append(cycles, append([]ObjectID(nil), path...))
+					}
+				} else if n > start && !containsID(path, n) {
+					visit(n)
+				}
+			}
+			path = path[:len(path)-1]
+		}
+		visit(start)
+	}
+	return cycles
+}
+
+func containsID(ids []ObjectID, id ObjectID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package kurento
+
+import "testing"
+
+const lintPipeline = "p_kurento.MediaPipeline"
+
+func lintEdges(pairs ...[2]ObjectID) []ConnectionSnapshot {
+	var edges []ConnectionSnapshot
+	for _, p := range pairs {
+		for _, media := range []MediaType{MEDIATYPE_AUDIO, MEDIATYPE_VIDEO} {
+			edges = append(edges, ConnectionSnapshot{Source: p[0], Sink: p[1], Type: media})
+		}
+	}
+	return edges
+}
+
+func TestLintOneToOneCall(t *testing.T) {
+	caller := ObjectID(lintPipeline + "/a_kurento.WebRtcEndpoint")
+	callee := ObjectID(lintPipeline + "/b_kurento.WebRtcEndpoint")
+	topology := &Topology{
+		Pipeline: lintPipeline,
+		Nodes: []TopologyNode{
+			{Id: caller, Type: "WebRtcEndpoint"},
+			{Id: callee, Type: "WebRtcEndpoint"},
+		},
+		Edges: lintEdges([2]ObjectID{caller, callee}, [2]ObjectID{callee, caller}),
+	}
+	if findings := LintTopology(topology); len(findings) != 0 {
+		t.Errorf("one-to-one call: %+v", findings)
+	}
+}
+
+func TestLintGroupCall(t *testing.T) {
+	hub := ObjectID(lintPipeline + "/h_kurento.Composite")
+	topology := &Topology{
+		Pipeline: lintPipeline,
+		Nodes:    []TopologyNode{{Id: hub, Type: "Composite"}},
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		ep := ObjectID(lintPipeline + "/" + id + "_kurento.WebRtcEndpoint")
+		port := ObjectID(lintPipeline + "/" + id + "port_kurento.HubPort")
+		topology.Nodes = append(topology.Nodes,
+			TopologyNode{Id: ep, Type: "WebRtcEndpoint"},
+			TopologyNode{Id: port, Type: "HubPort", Hub: hub})
+		topology.Edges = append(topology.Edges, lintEdges([2]ObjectID{ep, port}, [2]ObjectID{port, ep})...)
+	}
+	if findings := LintTopology(topology); len(findings) != 0 {
+		t.Errorf("group call: %+v", findings)
+	}
+}
+
+func TestLintCycle(t *testing.T) {
+	a := ObjectID(lintPipeline + "/a_kurento.PassThrough")
+	b := ObjectID(lintPipeline + "/b_kurento.PassThrough")
+	c := ObjectID(lintPipeline + "/c_kurento.PassThrough")
+	topology := &Topology{
+		Pipeline: lintPipeline,
+		Nodes: []TopologyNode{
+			{Id: a, Type: "PassThrough"},
+			{Id: b, Type: "PassThrough"},
+			{Id: c, Type: "PassThrough"},
+		},
+		Edges: lintEdges([2]ObjectID{a, b}, [2]ObjectID{b, c}, [2]ObjectID{c, a}, [2]ObjectID{a, a}),
+	}
+	findings := LintTopology(topology)
+	if len(findings) != 1 || findings[0].Code != LINTCODE_CYCLE || findings[0].Object != a || findings[0].Peer != c {
+		t.Errorf("3 elements cycle: %+v", findings)
+	}
+}
@@ -3,8 +3,9 @@ package kurento
 import "fmt"
 
 type IMixer interface {
-	Connect(media MediaType, source HubPort, sink HubPort) error
-	Disconnect(media MediaType, source HubPort, sink HubPort) error
+	IHub
+	Connect(media MediaType, source IHubPort, sink IHubPort) error
+	Disconnect(media MediaType, source IHubPort, sink IHubPort) error
 }
 
 // A `Hub` that allows routing of video between arbitrary port pairs and mixing of
@@ -30,7 +31,7 @@ func (elem *Mixer) getConstructorParams(from IMediaObject, options map[string]in
 
 // Connects each corresponding :rom:enum:`MediaType` of the given source port with
 // the sink port.
-func (elem *Mixer) Connect(media MediaType, source HubPort, sink HubPort) error {
+func (elem *Mixer) Connect(media MediaType, source IHubPort, sink IHubPort) error {
 	req := elem.getInvokeRequest()
 
 	params := make(map[string]interface{})
@@ -44,13 +45,10 @@ func (elem *Mixer) Connect(media MediaType, source HubPort, sink HubPort) error
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -63,7 +61,7 @@ func (elem *Mixer) Connect(media MediaType, source HubPort, sink HubPort) error
 
 // Disonnects each corresponding :rom:enum:`MediaType` of the given source port
 // from the sink port.
-func (elem *Mixer) Disconnect(media MediaType, source HubPort, sink HubPort) error {
+func (elem *Mixer) Disconnect(media MediaType, source IHubPort, sink IHubPort) error {
 	req := elem.getInvokeRequest()
 
 	params := make(map[string]interface{})
@@ -77,13 +75,10 @@ func (elem *Mixer) Disconnect(media MediaType, source HubPort, sink HubPort) err
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
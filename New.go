@@ -0,0 +1,60 @@
+package kurento
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// New creates an object of type T, e.g. *WebRtcEndpoint, from "parent" that is
+// the MediaPipeline for most elements or the Hub for a HubPort. The returned
+// object is bound to the parent connection and created in the media server.
+//
+//	ep, err := kurento.New[*kurento.WebRtcEndpoint](ctx, pipeline, nil)
+func New[T IMediaObject](ctx context.Context, parent IMediaObject, options map[string]interface{}) (T, error) {
+	var zero T
+
+	t := reflect.TypeOf(zero)
+	if t == nil {
+		return zero, fmt.Errorf("kurento: New needs a concrete type, not an interface")
+	}
+	registry.RLock()
+	typeName, ok := registry.names[t]
+	registry.RUnlock()
+	if !ok {
+		return zero, fmt.Errorf("kurento: type %s is not registered, use Register to add it", t)
+	}
+
+	if parent == nil || reflect.ValueOf(parent).IsNil() {
+		return zero, fmt.Errorf("kurento: cannot create %s without parent", typeName)
+	}
+	p := parent.mediaObject()
+	if err := p.checkCreated(false); err != nil {
+		return zero, fmt.Errorf("kurento: cannot create %s from %T: %s", typeName, parent, err.Message)
+	}
+
+	// the type name may have been registered again with another Go type
+	m, err := NewObject(typeName)
+	if err != nil {
+		return zero, err
+	}
+	obj, ok := m.(T)
+	if !ok {
+		return zero, fmt.Errorf("kurento: %s is registered as %T, not %s", typeName, m, t)
+	}
+	if err := p.createContext(ctx, obj, options); err != nil {
+		return zero, err
+	}
+	return obj, nil
+}
+
+// NewPipeline creates a MediaPipeline on the connection.
+func (c *Connection) NewPipeline(ctx context.Context) (*MediaPipeline, error) {
+	pipeline := new(MediaPipeline)
+	elem := &MediaObject{}
+	elem.setConnection(c)
+	if err := elem.createContext(ctx, pipeline, nil); err != nil {
+		return nil, err
+	}
+	return pipeline, nil
+}
@@ -0,0 +1,95 @@
+package kurento
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type firstTestFilter struct {
+	Filter
+}
+
+type secondTestFilter struct {
+	Filter
+}
+
+type unregisteredFilter struct {
+	Filter
+}
+
+func TestNew(t *testing.T) {
+	k := newFakeKms(t)
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ep, err := New[*WebRtcEndpoint](ctx, pipeline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ep.Id.Type() != "kurento.WebRtcEndpoint" || ep.Id.PipelineID() != pipeline.Id {
+		t.Errorf("created %s", ep.Id)
+	}
+	if ep.connection != k.conn {
+		t.Error("endpoint is not bound to the connection of the pipeline")
+	}
+
+	hub, err := New[*Composite](ctx, pipeline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := New[*HubPort](ctx, hub, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creates := k.requests("create")
+	if got := creates[len(creates)-1].Params["constructorParams"]; got.(map[string]interface{})["hub"] != string(hub.Id) {
+		t.Errorf("HubPort created with %v", got)
+	}
+	if port.Id.PipelineID() != pipeline.Id {
+		t.Errorf("created %s", port.Id)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	k := newFakeKms(t)
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the type name is registered again with another Go type
+	Register("newtest.TestFilter", func() IMediaObject { return new(firstTestFilter) })
+	Register("newtest.TestFilter", func() IMediaObject { return new(secondTestFilter) })
+
+	var nilPipeline *MediaPipeline
+	tests := []struct {
+		name string
+		new  func() (IMediaObject, error)
+		err  string
+	}{
+		{"interface", func() (IMediaObject, error) { return New[IMediaElement](ctx, pipeline, nil) }, "concrete type"},
+		{"unregistered", func() (IMediaObject, error) { return New[*unregisteredFilter](ctx, pipeline, nil) }, "not registered"},
+		{"nil parent", func() (IMediaObject, error) { return New[*WebRtcEndpoint](ctx, nil, nil) }, "without parent"},
+		{"nil pipeline", func() (IMediaObject, error) { return New[*WebRtcEndpoint](ctx, nilPipeline, nil) }, "without parent"},
+		{"parent not created", func() (IMediaObject, error) { return New[*WebRtcEndpoint](ctx, new(MediaPipeline), nil) }, "cannot create WebRtcEndpoint"},
+		{"type mismatch", func() (IMediaObject, error) { return New[*firstTestFilter](ctx, pipeline, nil) }, "is registered as *kurento.secondTestFilter"},
+	}
+	for _, tt := range tests {
+		if _, err := tt.new(); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: error %v, want %q", tt.name, err, tt.err)
+		}
+	}
+	if n := len(k.requests("create")); n != 1 {
+		t.Errorf("%d create requests, want the pipeline only", n)
+	}
+
+	filter, err := New[*secondTestFilter](ctx, pipeline, nil)
+	if err != nil || filter.Id.Type() != "newtest.TestFilter" {
+		t.Errorf("New of the registered type = %v, %v", filter, err)
+	}
+}
@@ -0,0 +1,96 @@
+package kurento
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ObjectID is the identifier KMS gives to a media object. Identifiers are a
+// "/" separated path of "<uuid>_<module>.<Type>" parts, the first part being
+// the owning pipeline, e.g.
+//
+//	"<pipelineUUID>_kurento.MediaPipeline/<elementUUID>_kurento.WebRtcEndpoint"
+//
+// Some standalone objects, such as "manager_ServerManager", have no module.
+type ObjectID string
+
+// ParseObjectID parses and validates a KMS object identifier.
+func ParseObjectID(s string) (ObjectID, error) {
+	if s == "" {
+		return "", fmt.Errorf("kurento: empty object id")
+	}
+	parts := strings.Split(s, "/")
+	for _, part := range parts {
+		i := strings.LastIndex(part, "_")
+		if i <= 0 || i == len(part)-1 {
+			return "", fmt.Errorf("kurento: invalid object id %q, %q is not <uuid>_<type>", s, part)
+		}
+		if strings.HasPrefix(part[i+1:], ".") || strings.HasSuffix(part, ".") {
+			return "", fmt.Errorf("kurento: invalid object id %q, bad type in %q", s, part)
+		}
+	}
+	id := ObjectID(s)
+	if len(parts) > 1 && ObjectID(parts[0]).TypeName() != "MediaPipeline" {
+		return "", fmt.Errorf("kurento: invalid object id %q, %q is not a pipeline", s, parts[0])
+	}
+	return id, nil
+}
+
+// Implement fmt.Stringer interface
+func (id ObjectID) String() string {
+	return string(id)
+}
+
+// Return the last part of the identifier, that is the object itself.
+func (id ObjectID) last() string {
+	s := string(id)
+	return s[strings.LastIndex(s, "/")+1:]
+}
+
+// UUID returns the unique part of the identifier, without the type.
+func (id ObjectID) UUID() string {
+	last := id.last()
+	if i := strings.LastIndex(last, "_"); i >= 0 {
+		return last[:i]
+	}
+	return last
+}
+
+// Type returns the qualified type of the object, e.g. "kurento.WebRtcEndpoint".
+func (id ObjectID) Type() string {
+	last := id.last()
+	return last[strings.LastIndex(last, "_")+1:]
+}
+
+// Module returns the module of the object type, e.g. "kurento". It is empty
+// for types without module.
+func (id ObjectID) Module() string {
+	t := id.Type()
+	if i := strings.LastIndex(t, "."); i >= 0 {
+		return t[:i]
+	}
+	return ""
+}
+
+// TypeName returns the type of the object without module, e.g.
+// "WebRtcEndpoint".
+func (id ObjectID) TypeName() string {
+	t := id.Type()
+	return t[strings.LastIndex(t, ".")+1:]
+}
+
+// PipelineID returns the identifier of the pipeline owning the object, or the
+// identifier itself for a pipeline. It is empty for objects that do not belong
+// to a pipeline.
+func (id ObjectID) PipelineID() ObjectID {
+	first := ObjectID(strings.SplitN(string(id), "/", 2)[0])
+	if first.TypeName() != "MediaPipeline" {
+		return ""
+	}
+	return first
+}
+
+// IsPipeline tells if the identifier is a pipeline one.
+func (id ObjectID) IsPipeline() bool {
+	return !strings.Contains(string(id), "/") && id.TypeName() == "MediaPipeline"
+}
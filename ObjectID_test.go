@@ -0,0 +1,67 @@
+package kurento
+
+import "testing"
+
+func TestParseObjectID(t *testing.T) {
+	const (
+		pipeline = "6ba9067f-e5bf-4ba2-8b0b-bbc4e4e3c0a5_kurento.MediaPipeline"
+		element  = pipeline + "/d8fc3ce9-5a8a-4f2a-b5f7-13b2b1b6b2a4_kurento.WebRtcEndpoint"
+	)
+
+	valid := []struct {
+		id         string
+		typ        string
+		module     string
+		typeName   string
+		uuid       string
+		pipelineID ObjectID
+		isPipeline bool
+	}{
+		{pipeline, "kurento.MediaPipeline", "kurento", "MediaPipeline",
+			"6ba9067f-e5bf-4ba2-8b0b-bbc4e4e3c0a5", pipeline, true},
+		{element, "kurento.WebRtcEndpoint", "kurento", "WebRtcEndpoint",
+			"d8fc3ce9-5a8a-4f2a-b5f7-13b2b1b6b2a4", pipeline, false},
+		{pipeline + "/f0e1_platedetector.PlateDetectorFilter", "platedetector.PlateDetectorFilter",
+			"platedetector", "PlateDetectorFilter", "f0e1", pipeline, false},
+		{"manager_ServerManager", "ServerManager", "", "ServerManager", "manager", "", false},
+	}
+	for _, tt := range valid {
+		id, err := ParseObjectID(tt.id)
+		if err != nil {
+			t.Errorf("ParseObjectID(%q): %v", tt.id, err)
+			continue
+		}
+		if string(id) != tt.id {
+			t.Errorf("ParseObjectID(%q) = %q", tt.id, id)
+		}
+		if id.Type() != tt.typ || id.Module() != tt.module || id.TypeName() != tt.typeName {
+			t.Errorf("%q: type %q module %q name %q", tt.id, id.Type(), id.Module(), id.TypeName())
+		}
+		if id.UUID() != tt.uuid {
+			t.Errorf("%q: UUID %q, want %q", tt.id, id.UUID(), tt.uuid)
+		}
+		if id.PipelineID() != tt.pipelineID {
+			t.Errorf("%q: PipelineID %q, want %q", tt.id, id.PipelineID(), tt.pipelineID)
+		}
+		if id.IsPipeline() != tt.isPipeline {
+			t.Errorf("%q: IsPipeline %v", tt.id, id.IsPipeline())
+		}
+	}
+
+	malformed := []string{
+		"",
+		"no-type",
+		"_kurento.MediaPipeline",
+		"abc_",
+		"abc_.MediaPipeline",
+		"abc_kurento.",
+		pipeline + "/",
+		pipeline + "//abc_kurento.WebRtcEndpoint",
+		"abc_kurento.WebRtcEndpoint/def_kurento.PassThrough",
+	}
+	for _, s := range malformed {
+		if id, err := ParseObjectID(s); err == nil {
+			t.Errorf("ParseObjectID(%q) = %q, want an error", s, id)
+		}
+	}
+}
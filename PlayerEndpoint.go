@@ -3,6 +3,7 @@ package kurento
 import "fmt"
 
 type IPlayerEndpoint interface {
+	IUriEndpoint
 	Play() error
 }
 
@@ -39,13 +40,10 @@ func (elem *PlayerEndpoint) Play() error {
 		"operation": "play",
 		"object":    elem.Id,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
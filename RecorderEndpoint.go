@@ -3,6 +3,7 @@ package kurento
 import "fmt"
 
 type IRecorderEndpoint interface {
+	IUriEndpoint
 	Record() error
 }
 
@@ -38,13 +39,10 @@ func (elem *RecorderEndpoint) Record() error {
 		"operation": "record",
 		"object":    elem.Id,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
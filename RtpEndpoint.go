@@ -3,13 +3,15 @@ package kurento
 import "fmt"
 
 type IRtpEndpoint interface {
+	IBaseRtpEndpoint
+	isRtpEndpoint()
 }
 
 // Endpoint that provides bidirectional content delivery capabilities with remote
 // networked peers through RTP protocol. An `RtpEndpoint` contains paired sink
 // and source `MediaPad` for audio and video.
 type RtpEndpoint struct {
-	SdpEndpoint
+	BaseRtpEndpoint
 }
 
 // Return contructor params to be called by "Create".
@@ -26,3 +28,6 @@ func (elem *RtpEndpoint) getConstructorParams(from IMediaObject, options map[str
 	return ret
 
 }
+
+// Implement IRtpEndpoint
+func (elem *RtpEndpoint) isRtpEndpoint() {}
@@ -0,0 +1,216 @@
+package kurento
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Scope records the media objects and subscriptions created through it, e.g.
+// for a call, so that a single Close releases all of them. Scopes can be
+// nested: closing a scope closes its children first.
+type Scope struct {
+	conn   *Connection
+	parent *Scope
+
+	mu            sync.Mutex
+	objects       []IMediaObject
+	subscriptions []*Subscription
+	children      []*Scope
+	closed        bool
+}
+
+// ErrScopeClosed is returned when using a closed scope.
+var ErrScopeClosed = errors.New("kurento: scope is closed")
+
+// NewScope returns a root scope on the connection.
+func (c *Connection) NewScope() *Scope {
+	return &Scope{conn: c}
+}
+
+// NewScope returns a child scope, closed with its parent.
+func (s *Scope) NewScope() (*Scope, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrScopeClosed
+	}
+	child := &Scope{conn: s.conn, parent: s}
+	s.children = append(s.children, child)
+	return child, nil
+}
+
+// Track records an object created outside of the scope, e.g. with New.
+func (s *Scope) Track(m IMediaObject) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrScopeClosed
+	}
+	s.objects = append(s.objects, m)
+	return nil
+}
+
+// NewPipeline creates a pipeline on the scope connection and records it.
+func (s *Scope) NewPipeline(ctx context.Context) (*MediaPipeline, error) {
+	if s.isClosed() {
+		return nil, ErrScopeClosed
+	}
+	pipeline, err := s.conn.NewPipeline(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pipeline, s.track(ctx, pipeline)
+}
+
+// Create creates "m" from "parent" and records it.
+func (s *Scope) Create(ctx context.Context, parent IMediaObject, m IMediaObject, options map[string]interface{}) error {
+	if s.isClosed() {
+		return ErrScopeClosed
+	}
+	p := parent.mediaObject()
+	if err := p.checkCreated(false); err != nil {
+		return err
+	}
+	if err := p.createContext(ctx, m, options); err != nil {
+		return err
+	}
+	return s.track(ctx, m)
+}
+
+// Subscribe subscribes to "event" of "m" and records the subscription.
+func (s *Scope) Subscribe(ctx context.Context, m IMediaObject, event string, cb eventHandler) (*Subscription, error) {
+	if s.isClosed() {
+		return nil, ErrScopeClosed
+	}
+	sub, err := m.mediaObject().subscribeContext(ctx, event, cb)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		sub.Close(ctx)
+		return nil, ErrScopeClosed
+	}
+	s.subscriptions = append(s.subscriptions, sub)
+	return sub, nil
+}
+
+// Transfer moves an object, and the subscriptions on it, to another scope. If
+// the other scope is closed, they are left in this scope, unless it was
+// closed meanwhile too.
+func (s *Scope) Transfer(m IMediaObject, to *Scope) error {
+	if s == to {
+		return nil
+	}
+	id := m.mediaObject().Id
+
+	s.mu.Lock()
+	index := -1
+	for i, o := range s.objects {
+		if o.mediaObject().Id == id {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		s.mu.Unlock()
+		return errors.New("kurento: object " + string(id) + " is not in the scope")
+	}
+	s.objects = append(s.objects[:index], s.objects[index+1:]...)
+	var moved []*Subscription
+	subscriptions := s.subscriptions[:0]
+	for _, sub := range s.subscriptions {
+		if sub.Object == id {
+			moved = append(moved, sub)
+		} else {
+			subscriptions = append(subscriptions, sub)
+		}
+	}
+	s.subscriptions = subscriptions
+	s.mu.Unlock()
+
+	// scopes are not locked together, a Transfer the other way would deadlock
+	to.mu.Lock()
+	closed := to.closed
+	if !closed {
+		to.objects = append(to.objects, m)
+		to.subscriptions = append(to.subscriptions, moved...)
+	}
+	to.mu.Unlock()
+	if !closed {
+		return nil
+	}
+
+	// give it back rather than losing it
+	s.mu.Lock()
+	if !s.closed {
+		s.objects = append(s.objects, m)
+		s.subscriptions = append(s.subscriptions, moved...)
+	}
+	s.mu.Unlock()
+	return ErrScopeClosed
+}
+
+// Close closes the child scopes, then removes the subscriptions and releases
+// the objects in reverse creation order. Objects already released in the
+// media server, e.g. with their pipeline, are ignored.
+func (s *Scope) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	children, subscriptions, objects := s.children, s.subscriptions, s.objects
+	s.children, s.subscriptions, s.objects = nil, nil, nil
+	s.mu.Unlock()
+
+	var errs []error
+	for i := len(children) - 1; i >= 0; i-- {
+		errs = append(errs, children[i].Close(ctx))
+	}
+	for i := len(subscriptions) - 1; i >= 0; i-- {
+		if err := subscriptions[i].Close(ctx); !isGone(err) {
+			errs = append(errs, err)
+		}
+	}
+	for i := len(objects) - 1; i >= 0; i-- {
+		if err := objects[i].mediaObject().releaseContext(ctx); !isGone(err) {
+			errs = append(errs, err)
+		}
+	}
+
+	if s.parent != nil {
+		s.parent.removeChild(s)
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Scope) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// Record a created object, releasing it if the scope was closed meanwhile.
+func (s *Scope) track(ctx context.Context, m IMediaObject) error {
+	if err := s.Track(m); err != nil {
+		m.mediaObject().releaseContext(ctx)
+		return err
+	}
+	return nil
+}
+
+func (s *Scope) removeChild(child *Scope) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, c := range s.children {
+		if c == child {
+			s.children = append(s.children[:i], s.children[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package kurento
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// Return a scope holding an endpoint and a subscription on it, without
+// connection.
+func testScope(id ObjectID) (*Scope, *WebRtcEndpoint, *Subscription) {
+	ep := new(WebRtcEndpoint)
+	ep.setId(string(id))
+	sub := &Subscription{Object: id, Event: "IceCandidateFound", Id: "subscription-" + string(id)}
+	s := &Scope{objects: []IMediaObject{ep}, subscriptions: []*Subscription{sub}}
+	return s, ep, sub
+}
+
+func TestScopeTransfer(t *testing.T) {
+	s, ep, sub := testScope("p_kurento.MediaPipeline/a_kurento.WebRtcEndpoint")
+	to := new(Scope)
+	if err := s.Transfer(ep, to); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.objects) != 0 || len(s.subscriptions) != 0 {
+		t.Errorf("left %v %v", s.objects, s.subscriptions)
+	}
+	if len(to.objects) != 1 || to.objects[0] != ep || len(to.subscriptions) != 1 || to.subscriptions[0] != sub {
+		t.Errorf("moved %v %v", to.objects, to.subscriptions)
+	}
+}
+
+func TestScopeTransferNotFound(t *testing.T) {
+	s, _, sub := testScope("p_kurento.MediaPipeline/a_kurento.WebRtcEndpoint")
+	// a subscription on an object created outside of the scope
+	other := new(WebRtcEndpoint)
+	other.setId("p_kurento.MediaPipeline/b_kurento.WebRtcEndpoint")
+	otherSub := &Subscription{Object: other.Id, Event: "IceCandidateFound", Id: "subscription-b"}
+	s.subscriptions = append(s.subscriptions, otherSub)
+
+	to := new(Scope)
+	if err := s.Transfer(other, to); err == nil {
+		t.Error("object not in the scope transferred")
+	}
+	if len(s.subscriptions) != 2 || s.subscriptions[0] != sub || s.subscriptions[1] != otherSub {
+		t.Errorf("subscriptions %v", s.subscriptions)
+	}
+	if len(to.objects) != 0 || len(to.subscriptions) != 0 {
+		t.Errorf("moved %v %v", to.objects, to.subscriptions)
+	}
+}
+
+func TestScopeTransferClosed(t *testing.T) {
+	s, ep, sub := testScope("p_kurento.MediaPipeline/a_kurento.WebRtcEndpoint")
+	to := &Scope{closed: true}
+	if err := s.Transfer(ep, to); err != ErrScopeClosed {
+		t.Errorf("Transfer to a closed scope error %v", err)
+	}
+	if len(s.objects) != 1 || s.objects[0] != ep || len(s.subscriptions) != 1 || s.subscriptions[0] != sub {
+		t.Errorf("not given back %v %v", s.objects, s.subscriptions)
+	}
+}
+
+func TestScopeTransferBothWays(t *testing.T) {
+	a, ep, _ := testScope("p_kurento.MediaPipeline/a_kurento.WebRtcEndpoint")
+	b, other, _ := testScope("p_kurento.MediaPipeline/b_kurento.WebRtcEndpoint")
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			a.Transfer(ep, b)
+			b.Transfer(ep, a)
+		}()
+		go func() {
+			defer wg.Done()
+			b.Transfer(other, a)
+			a.Transfer(other, b)
+		}()
+		wg.Wait()
+	}
+	if len(a.objects) != 1 || a.objects[0] != ep || len(b.objects) != 1 || b.objects[0] != other {
+		t.Errorf("objects %v %v", a.objects, b.objects)
+	}
+}
+
+func TestScopeClose(t *testing.T) {
+	k := newFakeKms(t)
+	ctx := context.Background()
+	s := k.conn.NewScope()
+	pipeline, err := s.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := s.NewScope()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep := new(WebRtcEndpoint)
+	if err := child.Create(ctx, pipeline, ep, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{string(ep.Id), string(pipeline.Id)}
+	if got := k.released(); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("released %v, want %v", got, want)
+	}
+	if err := child.Track(ep); err != ErrScopeClosed {
+		t.Errorf("Track on a closed child error %v", err)
+	}
+}
@@ -0,0 +1,180 @@
+package kurento
+
+import (
+	"fmt"
+	"sync"
+)
+
+// State of the SDP offer/answer negotiation of an SdpEndpoint, as seen by this
+// client.
+type SdpState string
+
+// Implement fmt.Stringer interface
+func (t SdpState) String() string {
+	return string(t)
+}
+
+const (
+	// No negotiation in progress
+	SDPSTATE_STABLE SdpState = "stable"
+	// An offer was generated, waiting for ProcessAnswer
+	SDPSTATE_HAVE_LOCAL_OFFER SdpState = "have-local-offer"
+	// A remote offer is being processed
+	SDPSTATE_HAVE_REMOTE_OFFER SdpState = "have-remote-offer"
+)
+
+// SdpStateError is returned when an SDP operation is not valid in the
+// negotiation state, e.g. ProcessAnswer without a prior GenerateOffer. The
+// request is not sent to the media server.
+type SdpStateError struct {
+	Operation string
+	State     SdpState
+
+	// Operation in progress, if any
+	Pending string
+}
+
+func (e *SdpStateError) Error() string {
+	if e.Pending != "" {
+		return fmt.Sprintf("kurento: cannot %s while %s is in progress", e.Operation, e.Pending)
+	}
+	return fmt.Sprintf("kurento: cannot %s in SDP state %s", e.Operation, e.State)
+}
+
+// SdpNegotiation is the negotiation state of an SdpEndpoint with the last
+// descriptors exchanged through it.
+type SdpNegotiation struct {
+	State SdpState
+
+	// Last offer generated or answer returned by the endpoint, updated by
+	// ProcessAnswer
+	LocalDescriptor string
+
+	// Last offer or answer processed by the endpoint
+	RemoteDescriptor string
+}
+
+// Negotiation state of an SdpEndpoint, the zero value is stable.
+type negotiationState struct {
+	mu      sync.Mutex
+	state   SdpState
+	pending string
+	offer   string // remote offer being processed
+	local   string
+	remote  string
+	answer  string // answer of the last negotiation
+	restart string // ICE restart in progress
+
+	codecs CodecPreferences
+}
+
+// Negotiation returns the negotiation state and the descriptors exchanged
+// through this client. GetLocalSessionDescriptor and
+// GetRemoteSessionDescriptor return the same descriptors, or ask the media
+// server for those of an endpoint negotiated by another client.
+func (elem *SdpEndpoint) Negotiation() SdpNegotiation {
+	n := &elem.negotiation
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return SdpNegotiation{
+		State:            n.current(),
+		LocalDescriptor:  n.local,
+		RemoteDescriptor: n.remote,
+	}
+}
+
+// ResetNegotiation makes the negotiation stable again, e.g. to drop an offer
+// the remote peer never answered, and ends a pending ICE restart. Cached
+// descriptors are kept.
+func (elem *SdpEndpoint) ResetNegotiation() {
+	n := &elem.negotiation
+	n.mu.Lock()
+	n.state = SDPSTATE_STABLE
+	n.restart = ""
+	n.mu.Unlock()
+}
+
+func (n *negotiationState) current() SdpState {
+	if n.state == "" {
+		return SDPSTATE_STABLE
+	}
+	return n.state
+}
+
+// Mark "operation" pending if the state is "allowed". Must be called with mu
+// held.
+func (n *negotiationState) begin(operation string, allowed SdpState) error {
+	if n.pending != "" {
+		return &SdpStateError{Operation: operation, State: n.current(), Pending: n.pending}
+	}
+	if n.current() != allowed {
+		return &SdpStateError{Operation: operation, State: n.current()}
+	}
+	n.pending = operation
+	return nil
+}
+
+func (n *negotiationState) beginGenerateOffer() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.begin("generateOffer", SDPSTATE_STABLE)
+}
+
+func (n *negotiationState) endGenerateOffer(offer string, ok bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.pending = ""
+	if ok {
+		n.state = SDPSTATE_HAVE_LOCAL_OFFER
+		n.local = offer
+	}
+}
+
+func (n *negotiationState) beginProcessOffer(offer string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if err := n.begin("processOffer", SDPSTATE_STABLE); err != nil {
+		return err
+	}
+	n.state = SDPSTATE_HAVE_REMOTE_OFFER
+	n.offer = offer
+	return nil
+}
+
+// The state is stable again, which ends an ICE restart.
+func (n *negotiationState) endProcessOffer(answer string, ok bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.pending = ""
+	n.state = SDPSTATE_STABLE
+	n.restart = ""
+	if ok {
+		n.remote = n.offer
+		n.local = answer
+		n.answer = answer
+	}
+	n.offer = ""
+}
+
+func (n *negotiationState) beginProcessAnswer() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.begin("processAnswer", SDPSTATE_HAVE_LOCAL_OFFER)
+}
+
+// A failure leaves the offer pending, so that another answer can be processed.
+// A success ends an ICE restart, whichever method processed the answer.
+func (n *negotiationState) endProcessAnswer(answer string, updated string, ok bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.pending = ""
+	if ok {
+		n.state = SDPSTATE_STABLE
+		n.restart = ""
+		n.remote = answer
+		n.answer = answer
+		if updated != "" {
+			n.local = updated
+		}
+	}
+}
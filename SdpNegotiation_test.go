@@ -0,0 +1,155 @@
+package kurento
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// A step of a negotiation: an operation and whether the media server accepts
+// it.
+type sdpStep struct {
+	operation string
+	ok        bool
+}
+
+// Run the begin and end transitions of a step.
+func (n *negotiationState) run(s sdpStep) error {
+	switch s.operation {
+	case "generateOffer":
+		if err := n.beginGenerateOffer(); err != nil {
+			return err
+		}
+		n.endGenerateOffer("local-offer", s.ok)
+	case "processOffer":
+		if err := n.beginProcessOffer("remote-offer"); err != nil {
+			return err
+		}
+		n.endProcessOffer("local-answer", s.ok)
+	case "processAnswer":
+		if err := n.beginProcessAnswer(); err != nil {
+			return err
+		}
+		n.endProcessAnswer("remote-answer", "updated-offer", s.ok)
+	}
+	return nil
+}
+
+func TestSdpTransitions(t *testing.T) {
+	tests := []struct {
+		name   string
+		steps  []sdpStep
+		state  SdpState
+		local  string
+		remote string
+		err    *SdpStateError // of the last step
+	}{
+		{"offer", []sdpStep{{"generateOffer", true}}, SDPSTATE_HAVE_LOCAL_OFFER, "local-offer", "", nil},
+		{"failed offer", []sdpStep{{"generateOffer", false}}, SDPSTATE_STABLE, "", "", nil},
+		{"offer and answer", []sdpStep{{"generateOffer", true}, {"processAnswer", true}}, SDPSTATE_STABLE, "updated-offer", "remote-answer", nil},
+		{"failed answer", []sdpStep{{"generateOffer", true}, {"processAnswer", false}}, SDPSTATE_HAVE_LOCAL_OFFER, "local-offer", "", nil},
+		{"answer after a failed one", []sdpStep{{"generateOffer", true}, {"processAnswer", false}, {"processAnswer", true}}, SDPSTATE_STABLE, "updated-offer", "remote-answer", nil},
+		{"remote offer", []sdpStep{{"processOffer", true}}, SDPSTATE_STABLE, "local-answer", "remote-offer", nil},
+		{"failed remote offer", []sdpStep{{"processOffer", false}}, SDPSTATE_STABLE, "", "", nil},
+		{"renegotiation", []sdpStep{{"processOffer", true}, {"generateOffer", true}}, SDPSTATE_HAVE_LOCAL_OFFER, "local-offer", "remote-offer", nil},
+
+		{"answer without offer", []sdpStep{{"processAnswer", true}}, SDPSTATE_STABLE, "", "", &SdpStateError{Operation: "processAnswer", State: SDPSTATE_STABLE}},
+		{"answer twice", []sdpStep{{"generateOffer", true}, {"processAnswer", true}, {"processAnswer", true}}, SDPSTATE_STABLE, "updated-offer", "remote-answer", &SdpStateError{Operation: "processAnswer", State: SDPSTATE_STABLE}},
+		{"offer twice", []sdpStep{{"generateOffer", true}, {"generateOffer", true}}, SDPSTATE_HAVE_LOCAL_OFFER, "local-offer", "", &SdpStateError{Operation: "generateOffer", State: SDPSTATE_HAVE_LOCAL_OFFER}},
+		{"glare", []sdpStep{{"generateOffer", true}, {"processOffer", true}}, SDPSTATE_HAVE_LOCAL_OFFER, "local-offer", "", &SdpStateError{Operation: "processOffer", State: SDPSTATE_HAVE_LOCAL_OFFER}},
+	}
+	for _, tt := range tests {
+		var n negotiationState
+		var err error
+		for _, s := range tt.steps {
+			err = n.run(s)
+		}
+		var stateErr *SdpStateError
+		switch {
+		case tt.err == nil && err != nil:
+			t.Errorf("%s: error %v", tt.name, err)
+		case tt.err != nil && (!errors.As(err, &stateErr) || *stateErr != *tt.err):
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.err)
+		}
+		if n.current() != tt.state || n.local != tt.local || n.remote != tt.remote || n.pending != "" {
+			t.Errorf("%s: state %s, local %q, remote %q, pending %q, want %s, %q, %q",
+				tt.name, n.current(), n.local, n.remote, n.pending, tt.state, tt.local, tt.remote)
+		}
+	}
+}
+
+func TestSdpPending(t *testing.T) {
+	var n negotiationState
+	if err := n.beginGenerateOffer(); err != nil {
+		t.Fatal(err)
+	}
+	for _, begin := range []func() error{n.beginGenerateOffer, n.beginProcessAnswer, func() error { return n.beginProcessOffer("offer") }} {
+		var stateErr *SdpStateError
+		if err := begin(); !errors.As(err, &stateErr) || stateErr.Pending != "generateOffer" {
+			t.Errorf("error %v while generating an offer", err)
+		}
+	}
+	n.endGenerateOffer("offer", true)
+
+	if err := n.beginProcessAnswer(); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.beginProcessAnswer(); err == nil {
+		t.Error("two answers processed at once")
+	}
+}
+
+func TestSessionDescriptors(t *testing.T) {
+	k := newFakeKms(t)
+	k.invoke = func(object, operation string, params map[string]interface{}) (interface{}, *Error) {
+		switch operation {
+		case "generateOffer":
+			return "offer", nil
+		case "processAnswer":
+			return "updated-offer", nil
+		case "getLocalSessionDescriptor":
+			return "server-local", nil
+		case "getRemoteSessionDescriptor":
+			return "server-remote", nil
+		}
+		return nil, &Error{Code: -32601, Message: "unknown operation " + operation}
+	}
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := New[*WebRtcEndpoint](ctx, pipeline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// not negotiated through this client
+	if local, err := ep.GetLocalSessionDescriptor(); err != nil || local != "server-local" {
+		t.Errorf("GetLocalSessionDescriptor() = %q, %v", local, err)
+	}
+	if remote, err := ep.GetRemoteSessionDescriptor(); err != nil || remote != "server-remote" {
+		t.Errorf("GetRemoteSessionDescriptor() = %q, %v", remote, err)
+	}
+
+	if _, err := ep.ProcessAnswer("answer"); err == nil {
+		t.Error("answer processed without offer")
+	}
+	if _, err := ep.GenerateOffer(); err != nil {
+		t.Fatal(err)
+	}
+	if local, err := ep.GetLocalSessionDescriptor(); err != nil || local != "offer" {
+		t.Errorf("GetLocalSessionDescriptor() = %q, %v", local, err)
+	}
+	if _, err := ep.ProcessAnswer("answer"); err != nil {
+		t.Fatal(err)
+	}
+	local, _ := ep.GetLocalSessionDescriptor()
+	remote, _ := ep.GetRemoteSessionDescriptor()
+	if n := ep.Negotiation(); local != "updated-offer" || remote != "answer" || n.LocalDescriptor != local || n.RemoteDescriptor != remote || n.State != SDPSTATE_STABLE {
+		t.Errorf("descriptors %q, %q, negotiation %+v", local, remote, n)
+	}
+	if n := len(k.requests("invoke")); n != 4 {
+		t.Errorf("%d operations invoked, want 4", n)
+	}
+}
@@ -0,0 +1,152 @@
+package kurento
+
+import (
+	"context"
+	"fmt"
+)
+
+// Identifier of the server manager, there is one per server.
+const serverManagerId = "manager_ServerManager"
+
+// ServerManager returns the manager of the server the connection is bound to.
+func (c *Connection) ServerManager(ctx context.Context) (*ServerManager, error) {
+	req := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "describe",
+		"params": map[string]interface{}{
+			"object": serverManagerId,
+		},
+	}
+	res, err := waitResponse(ctx, c.Request(req))
+	if err != nil {
+		return nil, err
+	}
+	if t := res.Result["type"]; t != "" && canonicalTypeName(t) != "ServerManager" {
+		return nil, fmt.Errorf("kurento: %s is a %s, not a ServerManager", serverManagerId, t)
+	}
+
+	elem := new(ServerManager)
+	elem.setConnection(c)
+	elem.setId(serverManagerId)
+	return elem, nil
+}
+
+// Returns server information, version, modules, factories, etc. Info is
+// updated with the returned value.
+func (elem *ServerManager) GetInfo(ctx context.Context) (*ServerInfo, error) {
+	response, err := elem.invoke(ctx, "getInfo", nil)
+	if err != nil {
+		return nil, err
+	}
+	info := new(ServerInfo)
+	if err := response.decodeValue(info); err != nil {
+		return nil, err
+	}
+	elem.Info = info
+	return info, nil
+}
+
+// Returns all the pipelines available in the server. Pipelines is updated with
+// the returned value.
+func (elem *ServerManager) GetPipelines(ctx context.Context) ([]IMediaPipeline, error) {
+	response, err := elem.invoke(ctx, "getPipelines", nil)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	if err := response.decodeValue(&ids); err != nil {
+		return nil, err
+	}
+	pipelines := make([]IMediaPipeline, 0, len(ids))
+	for _, id := range ids {
+		if p, ok := decodeObjectRef(elem.connection, id).(IMediaPipeline); ok {
+			pipelines = append(pipelines, p)
+		}
+	}
+	elem.Pipelines = pipelines
+	return pipelines, nil
+}
+
+// Returns all active sessions in the server. Sessions is updated with the
+// returned value.
+func (elem *ServerManager) GetSessions(ctx context.Context) ([]string, error) {
+	response, err := elem.invoke(ctx, "getSessions", nil)
+	if err != nil {
+		return nil, err
+	}
+	sessions := []string{}
+	if err := response.decodeValue(&sessions); err != nil {
+		return nil, err
+	}
+	elem.Sessions = sessions
+	return sessions, nil
+}
+
+// Returns the metadata stored in the server. Metadata is updated with the
+// returned value.
+func (elem *ServerManager) GetMetadata(ctx context.Context) (string, error) {
+	response, err := elem.invoke(ctx, "getMetadata", nil)
+	if err != nil {
+		return "", err
+	}
+	var metadata string
+	if err := response.decodeValue(&metadata); err != nil {
+		return "", err
+	}
+	elem.Metadata = metadata
+	return metadata, nil
+}
+
+// Returns the amount of memory, in KiB, used by the server.
+func (elem *ServerManager) GetUsedMemory(ctx context.Context) (int64, error) {
+	response, err := elem.invoke(ctx, "getUsedMemory", nil)
+	if err != nil {
+		return 0, err
+	}
+	var mem int64
+	err = response.decodeValue(&mem)
+	return mem, err
+}
+
+// Returns the number of CPUs available to the server.
+func (elem *ServerManager) GetCpuCount(ctx context.Context) (int, error) {
+	response, err := elem.invoke(ctx, "getCpuCount", nil)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	err = response.decodeValue(&count)
+	return count, err
+}
+
+// Returns the CPU usage of the server, as a percentage, measured over
+// "interval" milliseconds. The interval must be positive.
+func (elem *ServerManager) GetUsedCpu(ctx context.Context, interval int) (float64, error) {
+	if interval <= 0 {
+		return 0, fmt.Errorf("kurento: invalid cpu usage interval %d", interval)
+	}
+	params := map[string]interface{}{"interval": interval}
+
+	response, err := elem.invoke(ctx, "getUsedCpu", params)
+	if err != nil {
+		return 0, err
+	}
+	var usage float64
+	err = response.decodeValue(&usage)
+	return usage, err
+}
+
+// Refresh populates Info, Pipelines, Sessions and Metadata from the server.
+func (elem *ServerManager) Refresh(ctx context.Context) error {
+	if _, err := elem.GetInfo(ctx); err != nil {
+		return err
+	}
+	if _, err := elem.GetPipelines(ctx); err != nil {
+		return err
+	}
+	if _, err := elem.GetSessions(ctx); err != nil {
+		return err
+	}
+	_, err := elem.GetMetadata(ctx)
+	return err
+}
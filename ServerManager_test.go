@@ -0,0 +1,37 @@
+package kurento
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetUsedCpu(t *testing.T) {
+	k := newFakeKms(t)
+	k.invoke = func(object, operation string, params map[string]interface{}) (interface{}, *Error) {
+		if operation != "getUsedCpu" || params["interval"] == nil {
+			return nil, &Error{Code: -32602, Message: "'interval' parameter is required"}
+		}
+		return 12.5, nil
+	}
+	manager := new(ServerManager)
+	manager.setConnection(k.conn)
+	manager.setId(serverManagerId)
+
+	ctx := context.Background()
+	usage, err := manager.GetUsedCpu(ctx, 500)
+	if err != nil || usage != 12.5 {
+		t.Errorf("GetUsedCpu(500) = %v, %v", usage, err)
+	}
+	if got := k.requests("invoke")[0].Params["operationParams"]; got.(map[string]interface{})["interval"] != 500.0 {
+		t.Errorf("operation params %v", got)
+	}
+
+	for _, interval := range []int{0, -1} {
+		if _, err := manager.GetUsedCpu(ctx, interval); err == nil {
+			t.Errorf("GetUsedCpu(%d) has no error", interval)
+		}
+	}
+	if n := len(k.requests("invoke")); n != 1 {
+		t.Errorf("%d operations invoked, want 1", n)
+	}
+}
@@ -0,0 +1,286 @@
+package kurento
+
+import (
+	"context"
+	"fmt"
+)
+
+// PipelineSnapshot is a copy of the topology of a running pipeline, that can be
+// encoded to JSON and restored on another media server.
+type PipelineSnapshot struct {
+	Pipeline    ObjectID             `json:"pipeline"`
+	Tags        []Tag                `json:"tags,omitempty"`
+	Elements    []ElementSnapshot    `json:"elements"`
+	Connections []ConnectionSnapshot `json:"connections"`
+}
+
+// ElementSnapshot is the state of an element of a pipeline.
+type ElementSnapshot struct {
+	Id   ObjectID `json:"id"`
+	Type string   `json:"type"`
+
+	// Hub of a HubPort
+	Hub ObjectID `json:"hub,omitempty"`
+
+	// Constructor options, if the element was created through this client
+	Options map[string]interface{} `json:"options,omitempty"`
+
+	// Properties read from the media server
+	Properties map[string]interface{} `json:"properties,omitempty"`
+
+	Tags []Tag `json:"tags,omitempty"`
+}
+
+// ConnectionSnapshot is a connection between two elements.
+type ConnectionSnapshot struct {
+	Source            ObjectID  `json:"source"`
+	Sink              ObjectID  `json:"sink"`
+	Type              MediaType `json:"type"`
+	SourceDescription string    `json:"sourceDescription,omitempty"`
+	SinkDescription   string    `json:"sinkDescription,omitempty"`
+}
+
+// Snapshot reads the elements of the pipeline, their properties, tags and
+// connections. Elements include the ports of hubs, the hub of a port is its
+// parent in the media server. Constructor options are only known for elements
+// created through this client.
+func (elem *MediaPipeline) Snapshot(ctx context.Context) (*PipelineSnapshot, error) {
+	tags, err := elem.getTagsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snap := &PipelineSnapshot{
+		Pipeline:    elem.Id,
+		Tags:        tags,
+		Elements:    []ElementSnapshot{},
+		Connections: []ConnectionSnapshot{},
+	}
+
+	// hubs are walked, and restored, before their ports
+	objects, err := elem.walk(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range objects {
+		e, err := snapshotElement(ctx, o)
+		if err != nil {
+			return nil, err
+		}
+		snap.Elements = append(snap.Elements, e)
+
+		connections, err := sinkConnections(ctx, o.object)
+		if err != nil {
+			return nil, err
+		}
+		snap.Connections = append(snap.Connections, connections...)
+	}
+	return snap, nil
+}
+
+// Read the state of an element.
+func snapshotElement(ctx context.Context, o pipelineObject) (ElementSnapshot, error) {
+	elem := o.object.mediaObject()
+	e := ElementSnapshot{
+		Id:         elem.Id,
+		Type:       canonicalTypeName(elem.Id.Type()),
+		Properties: make(map[string]interface{}),
+	}
+	if r, ok := elem.connection.createdRecord(elem.Id); ok {
+		e.Options = copyOptions(r.options)
+	}
+	if _, isPort := o.object.(IHubPort); isPort {
+		e.Hub = o.parent
+	}
+
+	tags, err := elem.getTagsContext(ctx)
+	if err != nil {
+		return e, err
+	}
+	e.Tags = tags
+
+	info, _ := registeredInfo(e.Type)
+	for _, name := range info.properties {
+		response, err := elem.invoke(ctx, "get"+upperFirst(name), nil)
+		if err != nil {
+			if ctx.Err() != nil {
+				return e, ctx.Err()
+			}
+			// not supported by this server
+			continue
+		}
+		var value interface{}
+		if err := response.decodeValue(&value); err == nil && value != nil {
+			e.Properties[name] = value
+		}
+	}
+	return e, nil
+}
+
+// An object of a pipeline with its parent in the media server: the pipeline,
+// or the hub of a HubPort.
+type pipelineObject struct {
+	object IMediaObject
+	parent ObjectID
+}
+
+// Return the objects of the pipeline, each hub followed by its ports. Hubs
+// released during the walk are skipped.
+func (elem *MediaPipeline) walk(ctx context.Context) ([]pipelineObject, error) {
+	var objects []pipelineObject
+	var visit func(parent *MediaObject) error
+	visit = func(parent *MediaObject) error {
+		children, err := childrenOf(ctx, parent)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			objects = append(objects, pipelineObject{child, parent.Id})
+			if _, isHub := child.(IHub); !isHub {
+				continue
+			}
+			if err := visit(child.mediaObject()); err != nil && !isGone(err) {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := visit(&elem.MediaObject); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// Return the direct children of an object.
+func childrenOf(ctx context.Context, elem *MediaObject) ([]IMediaObject, error) {
+	response, err := elem.invoke(ctx, "getChildren", nil)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	if err := response.decodeValue(&ids); err != nil {
+		return nil, err
+	}
+	children := make([]IMediaObject, 0, len(ids))
+	for _, id := range ids {
+		children = append(children, decodeObjectRef(elem.connection, id))
+	}
+	return children, nil
+}
+
+// Return the connections an element sends media through.
+func sinkConnections(ctx context.Context, m IMediaObject) ([]ConnectionSnapshot, error) {
+	if _, ok := m.(IMediaElement); !ok {
+		return nil, nil
+	}
+	response, err := m.mediaObject().invoke(ctx, "getSinkConnections", nil)
+	if err != nil {
+		return nil, err
+	}
+	connections := []ConnectionSnapshot{}
+	err = response.decodeValue(&connections)
+	return connections, err
+}
+
+// Restore creates a pipeline on the connection from a snapshot. It returns the
+// created objects by snapshot ID, the pipeline included. If a step fails,
+// everything created so far is released.
+func Restore(ctx context.Context, conn *Connection, snap *PipelineSnapshot) (map[ObjectID]IMediaObject, error) {
+	b := conn.NewBuilder(ctx)
+	objects := map[ObjectID]IMediaObject{
+		snap.Pipeline: b.Pipeline(),
+	}
+	b.Do(func(ctx context.Context) error {
+		return addTags(ctx, b.Pipeline(), snap.Tags)
+	})
+
+	for _, e := range snap.Elements {
+		e := e
+		m, err := NewObject(e.Type)
+		if err != nil {
+			b.Do(func(context.Context) error { return err })
+			break
+		}
+
+		var parent IMediaObject = b.Pipeline()
+		if _, isPort := m.(IHubPort); isPort && e.Hub == "" {
+			b.Do(func(context.Context) error {
+				return fmt.Errorf("kurento: HubPort %s has no hub in the snapshot", e.Id)
+			})
+			break
+		}
+		if e.Hub != "" {
+			parent = objects[e.Hub]
+			if parent == nil {
+				b.Do(func(context.Context) error {
+					return fmt.Errorf("kurento: hub %s of %s is not in the snapshot", e.Hub, e.Id)
+				})
+				break
+			}
+		}
+
+		// properties that are constructor options are given at creation
+		options := make(map[string]interface{})
+		for name, value := range e.Options {
+			options[name] = value
+		}
+		setters := make(map[string]interface{})
+		info, _ := registeredInfo(e.Type)
+		for name, value := range e.Properties {
+			if _, isOption := info.options[name]; isOption {
+				if _, ok := options[name]; !ok {
+					options[name] = value
+				}
+			} else {
+				setters[name] = value
+			}
+		}
+
+		b.CreateFrom(parent, m, options)
+		b.Do(func(ctx context.Context) error {
+			for name, value := range setters {
+				params := map[string]interface{}{name: value}
+				if _, err := m.mediaObject().invoke(ctx, "set"+upperFirst(name), params); err != nil {
+					return fmt.Errorf("kurento: cannot set %s of %s: %w", name, e.Id, err)
+				}
+			}
+			return addTags(ctx, m, e.Tags)
+		})
+		objects[e.Id] = m
+	}
+
+	for _, c := range snap.Connections {
+		c := c
+		b.Do(func(ctx context.Context) error {
+			source, ok := objects[c.Source].(IMediaElement)
+			sink, ok2 := objects[c.Sink].(IMediaElement)
+			if !ok || !ok2 {
+				return fmt.Errorf("kurento: connection %s -> %s refers to elements not in the snapshot", c.Source, c.Sink)
+			}
+			params := make(map[string]interface{})
+			setIfNotEmpty(params, "sink", sink)
+			setIfNotEmpty(params, "mediaType", c.Type)
+			setIfNotEmpty(params, "sourceMediaDescription", c.SourceDescription)
+			setIfNotEmpty(params, "sinkMediaDescription", c.SinkDescription)
+			_, err := source.mediaObject().invoke(ctx, "connect", params)
+			return err
+		})
+	}
+
+	if _, err := b.Build(); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// Add tags to an object.
+func addTags(ctx context.Context, m IMediaObject, tags []Tag) error {
+	for _, tag := range tags {
+		params := make(map[string]interface{})
+		setIfNotEmpty(params, "key", tag.Key)
+		setIfNotEmpty(params, "value", tag.Value)
+		if _, err := m.mediaObject().invoke(ctx, "addTag", params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
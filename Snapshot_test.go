@@ -0,0 +1,81 @@
+package kurento
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshotHubPorts(t *testing.T) {
+	k := newFakeKms(t)
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := New[*WebRtcEndpoint](ctx, pipeline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub, err := New[*Composite](ctx, pipeline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := New[*HubPort](ctx, hub, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ep.Connect(port, MEDIATYPE_VIDEO, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	// created by another client
+	otherHub := k.add("Dispatcher", string(pipeline.Id))
+	otherPort := k.add("HubPort", otherHub)
+
+	snap, err := pipeline.Snapshot(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []ElementSnapshot
+	for _, e := range snap.Elements {
+		got = append(got, ElementSnapshot{Id: e.Id, Type: e.Type, Hub: e.Hub})
+	}
+	want := []ElementSnapshot{
+		{Id: ep.Id, Type: "WebRtcEndpoint"},
+		{Id: hub.Id, Type: "Composite"},
+		{Id: port.Id, Type: "HubPort", Hub: hub.Id},
+		{Id: ObjectID(otherHub), Type: "Dispatcher"},
+		{Id: ObjectID(otherPort), Type: "HubPort", Hub: ObjectID(otherHub)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("elements %+v, want %+v", got, want)
+	}
+	wantConnections := []ConnectionSnapshot{{Source: ep.Id, Sink: port.Id, Type: MEDIATYPE_VIDEO}}
+	if !reflect.DeepEqual(snap.Connections, wantConnections) {
+		t.Errorf("connections %+v, want %+v", snap.Connections, wantConnections)
+	}
+
+	// the ports are restored from their hub
+	objects, err := Restore(ctx, k.conn, snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{string(port.Id), otherPort} {
+		restored := objects[ObjectID(id)].mediaObject().Id
+		if restored.Type() != "kurento.HubPort" || restored.PipelineID() == pipeline.Id {
+			t.Errorf("port %s restored as %s", id, restored)
+		}
+	}
+	hubs := map[interface{}]bool{}
+	for _, c := range k.requests("create") {
+		constructor, _ := c.Params["constructorParams"].(map[string]interface{})
+		if hub, ok := constructor["hub"]; ok {
+			hubs[hub] = true
+		}
+	}
+	for _, id := range []ObjectID{hub.Id, ObjectID(otherHub)} {
+		if restored := objects[id].mediaObject().Id; !hubs[string(restored)] {
+			t.Errorf("no port restored from %s", restored)
+		}
+	}
+}
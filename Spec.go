@@ -0,0 +1,210 @@
+package kurento
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// PipelineSpec describes a media topology: the elements of a pipeline and
+// their connections. Specs are loaded from JSON with LoadSpec, or from YAML
+// with LoadSpecYAML and the Unmarshal function of a YAML package; this package
+// does not depend on one.
+//
+//	{
+//	  "elements": [
+//	    {"name": "player", "type": "PlayerEndpoint", "options": {"uri": "file:///tmp/video.webm"}},
+//	    {"name": "viewer", "type": "WebRtcEndpoint"},
+//	    {"name": "recorder", "type": "RecorderEndpoint", "options": {"uri": "file:///tmp/out.webm"}}
+//	  ],
+//	  "connections": [
+//	    {"from": "player", "to": "viewer"},
+//	    {"from": "player", "to": "recorder", "media": "VIDEO"}
+//	  ]
+//	}
+type PipelineSpec struct {
+	Elements    []ElementSpec    `json:"elements" yaml:"elements"`
+	Connections []ConnectionSpec `json:"connections" yaml:"connections"`
+}
+
+// ElementSpec describes an element to create.
+type ElementSpec struct {
+	// Name of the element in the spec, used by connections
+	Name string `json:"name" yaml:"name"`
+
+	// Registered KMS type, e.g. "WebRtcEndpoint"
+	Type string `json:"type" yaml:"type"`
+
+	// Name of the Hub a HubPort belongs to
+	Hub string `json:"hub,omitempty" yaml:"hub,omitempty"`
+
+	// Constructor options
+	Options map[string]interface{} `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// ConnectionSpec describes a connection between two elements.
+type ConnectionSpec struct {
+	From string `json:"from" yaml:"from"`
+	To   string `json:"to" yaml:"to"`
+
+	// Connected media type, all media types if empty
+	Media MediaType `json:"media,omitempty" yaml:"media,omitempty"`
+}
+
+// LoadSpec decodes a JSON spec and validates it.
+func LoadSpec(r io.Reader) (*PipelineSpec, error) {
+	spec := new(PipelineSpec)
+	if err := json.NewDecoder(r).Decode(spec); err != nil {
+		return nil, fmt.Errorf("kurento: cannot decode spec: %w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// LoadSpecYAML decodes a YAML spec with "unmarshal", e.g. yaml.Unmarshal of
+// gopkg.in/yaml.v3, and validates it.
+//
+//	spec, err := kurento.LoadSpecYAML(f, yaml.Unmarshal)
+func LoadSpecYAML(r io.Reader, unmarshal func(in []byte, out interface{}) error) (*PipelineSpec, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	spec := new(PipelineSpec)
+	if err := unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("kurento: cannot decode spec: %w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// Validate checks element types and options, hubs and connections.
+func (spec *PipelineSpec) Validate() error {
+	objects := make(map[string]IMediaObject)
+	for i, e := range spec.Elements {
+		if e.Name == "" {
+			return fmt.Errorf("kurento: spec element %d has no name", i)
+		}
+		if _, ok := objects[e.Name]; ok {
+			return fmt.Errorf("kurento: spec element %q is defined twice", e.Name)
+		}
+		m, err := NewObject(e.Type)
+		if err != nil {
+			return fmt.Errorf("kurento: spec element %q: %w", e.Name, err)
+		}
+
+		switch m.(type) {
+		case IHubPort:
+			if _, ok := objects[e.Hub].(IHub); !ok {
+				return fmt.Errorf("kurento: spec element %q: hub %q is not a hub defined before the port", e.Name, e.Hub)
+			}
+		case IMediaElement, IHub:
+			if e.Hub != "" {
+				return fmt.Errorf("kurento: spec element %q: only a HubPort can have a hub", e.Name)
+			}
+		default:
+			return fmt.Errorf("kurento: spec element %q: %s is neither an element nor a hub", e.Name, e.Type)
+		}
+
+		if err := checkSpecOptions(canonicalTypeName(e.Type), e.Options); err != nil {
+			return fmt.Errorf("kurento: spec element %q: %w", e.Name, err)
+		}
+		objects[e.Name] = m
+	}
+
+	for i, c := range spec.Connections {
+		for _, name := range []string{c.From, c.To} {
+			if _, ok := objects[name].(IMediaElement); !ok {
+				return fmt.Errorf("kurento: spec connection %d: %q is not a defined element", i, name)
+			}
+		}
+		switch c.Media {
+		case "", MEDIATYPE_AUDIO, MEDIATYPE_VIDEO, MEDIATYPE_DATA:
+		default:
+			return fmt.Errorf("kurento: spec connection %d: unknown media type %q", i, c.Media)
+		}
+	}
+	return nil
+}
+
+// Check option names and value kinds of a known type. Types registered by
+// users accept any option.
+func checkSpecOptions(typeName string, options map[string]interface{}) error {
+	info, known := registeredInfo(typeName)
+	if !known {
+		return nil
+	}
+	for name, value := range options {
+		kind, ok := info.options[name]
+		if !ok {
+			return fmt.Errorf("unknown option %q for %s", name, typeName)
+		}
+		valid := false
+		switch value.(type) {
+		case string:
+			valid = kind == optionString
+		case bool:
+			valid = kind == optionBool
+		case float64, float32, int, int64, uint, uint64:
+			valid = kind == optionNumber
+		case map[string]interface{}:
+			valid = kind == optionObject
+		}
+		if !valid {
+			return fmt.Errorf("option %q has an invalid value %v", name, value)
+		}
+	}
+	return nil
+}
+
+// PipelineHandle gives access to the objects created by Apply.
+type PipelineHandle struct {
+	Pipeline *MediaPipeline
+
+	// Created objects by spec name
+	Objects map[string]IMediaObject
+}
+
+// Element returns the element created for a spec name, nil if there is no
+// such element.
+func (h *PipelineHandle) Element(name string) IMediaElement {
+	elem, _ := h.Objects[name].(IMediaElement)
+	return elem
+}
+
+// Apply creates a pipeline on the connection with the elements and connections
+// of the spec. If a step fails, everything created so far is released.
+func (spec *PipelineSpec) Apply(ctx context.Context, conn *Connection) (*PipelineHandle, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	b := conn.NewBuilder(ctx)
+	handle := &PipelineHandle{
+		Pipeline: b.Pipeline(),
+		Objects:  make(map[string]IMediaObject),
+	}
+
+	for _, e := range spec.Elements {
+		m, _ := NewObject(e.Type)
+		var parent IMediaObject = handle.Pipeline
+		if e.Hub != "" {
+			parent = handle.Objects[e.Hub]
+		}
+		b.CreateFrom(parent, m, e.Options)
+		handle.Objects[e.Name] = m
+	}
+	for _, c := range spec.Connections {
+		b.Connect(handle.Element(c.From), handle.Element(c.To), c.Media)
+	}
+
+	if _, err := b.Build(); err != nil {
+		return nil, err
+	}
+	return handle, nil
+}
@@ -0,0 +1,82 @@
+package kurento
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckSpecOptions(t *testing.T) {
+	tests := []struct {
+		typeName string
+		options  map[string]interface{}
+		valid    bool
+	}{
+		{"PlayerEndpoint", nil, true},
+		{"PlayerEndpoint", map[string]interface{}{"uri": "file:///tmp/a.webm", "useEncodedMedia": true, "networkCache": 2000.0}, true},
+		{"PlayerEndpoint", map[string]interface{}{"uri": 1}, false},
+		{"PlayerEndpoint", map[string]interface{}{"networkCache": "2000"}, false},
+		{"RecorderEndpoint", map[string]interface{}{"stopOnEndOfStream": "yes"}, false},
+		{"WebRtcEndpoint", map[string]interface{}{"recvonly": true, "useDataChannels": false, "qosDscp": "AF41"}, true},
+		{"RtpEndpoint", map[string]interface{}{"useIpv6": true}, true},
+		{"RtpEndpoint", map[string]interface{}{"crypto": map[string]interface{}{"crypto": "AES_128_CM_HMAC_SHA1_80", "key": "0123456789abcdef0123456789abcd"}}, true},
+		{"RtpEndpoint", map[string]interface{}{"crypto": "AES_128_CM_HMAC_SHA1_80"}, false},
+		{"RtpEndpoint", map[string]interface{}{"uri": "rtp://10.0.0.1"}, false},
+		{"PassThrough", map[string]interface{}{"uri": ""}, false},
+		{"Composite", nil, true},
+		{"platedetector.PlateDetectorFilter", map[string]interface{}{"anything": []interface{}{1}}, true},
+	}
+	for _, tt := range tests {
+		err := checkSpecOptions(tt.typeName, tt.options)
+		if (err == nil) != tt.valid {
+			t.Errorf("checkSpecOptions(%s, %v) = %v, want valid %v", tt.typeName, tt.options, err, tt.valid)
+		}
+	}
+}
+
+func TestSpecValidate(t *testing.T) {
+	valid := `{
+		"elements": [
+			{"name": "player", "type": "PlayerEndpoint", "options": {"uri": "file:///tmp/video.webm"}},
+			{"name": "viewer", "type": "kurento.WebRtcEndpoint"},
+			{"name": "mixer", "type": "Composite"},
+			{"name": "port", "type": "HubPort", "hub": "mixer"},
+			{"name": "rtp", "type": "RtpEndpoint", "options": {"useIpv6": true}}
+		],
+		"connections": [
+			{"from": "player", "to": "viewer"},
+			{"from": "player", "to": "port", "media": "VIDEO"},
+			{"from": "port", "to": "rtp"}
+		]
+	}`
+	spec, err := LoadSpec(strings.NewReader(valid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.Elements) != 5 || len(spec.Connections) != 3 {
+		t.Errorf("loaded %+v", spec)
+	}
+
+	tests := []struct {
+		spec string
+		err  string
+	}{
+		{`{"elements": [{"type": "WebRtcEndpoint"}]}`, "has no name"},
+		{`{"elements": [{"name": "a", "type": "WebRtcEndpoint"}, {"name": "a", "type": "PassThrough"}]}`, "defined twice"},
+		{`{"elements": [{"name": "a", "type": "Unknown"}]}`, "unknown type"},
+		{`{"elements": [{"name": "a", "type": "MediaPipeline"}]}`, "neither an element nor a hub"},
+		{`{"elements": [{"name": "p", "type": "HubPort"}]}`, "is not a hub defined before the port"},
+		{`{"elements": [{"name": "p", "type": "HubPort", "hub": "m"}, {"name": "m", "type": "Composite"}]}`, "is not a hub defined before the port"},
+		{`{"elements": [{"name": "a", "type": "PassThrough"}, {"name": "p", "type": "HubPort", "hub": "a"}]}`, "is not a hub defined before the port"},
+		{`{"elements": [{"name": "m", "type": "Composite"}, {"name": "a", "type": "PassThrough", "hub": "m"}]}`, "only a HubPort can have a hub"},
+		{`{"elements": [{"name": "a", "type": "PlayerEndpoint", "options": {"url": "x"}}]}`, "unknown option \"url\""},
+		{`{"elements": [{"name": "a", "type": "PassThrough"}], "connections": [{"from": "a", "to": "b"}]}`, "\"b\" is not a defined element"},
+		{`{"elements": [{"name": "m", "type": "Composite"}, {"name": "a", "type": "PassThrough"}], "connections": [{"from": "a", "to": "m"}]}`, "\"m\" is not a defined element"},
+		{`{"elements": [{"name": "a", "type": "PassThrough"}], "connections": [{"from": "a", "to": "a", "media": "TEXT"}]}`, "unknown media type"},
+	}
+	for _, tt := range tests {
+		_, err := LoadSpec(strings.NewReader(tt.spec))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("LoadSpec(%s) error %v, want %q", tt.spec, err, tt.err)
+		}
+	}
+}
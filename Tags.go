@@ -0,0 +1,103 @@
+package kurento
+
+import (
+	"context"
+	"errors"
+)
+
+// TagMap returns tags as a key to value map.
+func TagMap(tags []Tag) map[string]string {
+	m := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		m[tag.Key] = tag.Value
+	}
+	return m
+}
+
+// SetTags adds the given tags to the object, e.g. to record the room or the
+// tenant it belongs to, so that it can be found with FindByTag later.
+func (elem *MediaObject) SetTags(ctx context.Context, tags map[string]string) error {
+	for key, value := range tags {
+		params := make(map[string]interface{})
+		setIfNotEmpty(params, "key", key)
+		setIfNotEmpty(params, "value", value)
+		if _, err := elem.invoke(ctx, "addTag", params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Tell if an object has the tag, with any value if "value" is empty.
+func hasTag(ctx context.Context, m IMediaObject, key, value string) (bool, error) {
+	tags, err := m.mediaObject().getTagsContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	v, ok := TagMap(tags)[key]
+	return ok && (value == "" || v == value), nil
+}
+
+// FindByTag returns the objects of the pipeline, hub ports included, having
+// the tag "key" with the given value, or with any value if "value" is empty.
+func (elem *MediaPipeline) FindByTag(ctx context.Context, key, value string) ([]IMediaObject, error) {
+	objects, err := elem.walk(ctx)
+	if err != nil {
+		return nil, err
+	}
+	found := []IMediaObject{}
+	for _, o := range objects {
+		ok, err := hasTag(ctx, o.object, key, value)
+		if isGone(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if ok {
+			found = append(found, o.object)
+		}
+	}
+	return found, nil
+}
+
+// FindByTag returns the pipelines of the server, and the objects in them,
+// having the tag "key" with the given value, or with any value if "value" is
+// empty.
+func (elem *ServerManager) FindByTag(ctx context.Context, key, value string) ([]IMediaObject, error) {
+	pipelines, err := elem.GetPipelines(ctx)
+	if err != nil {
+		return nil, err
+	}
+	found := []IMediaObject{}
+	for _, p := range pipelines {
+		ok, err := hasTag(ctx, p, key, value)
+		if isGone(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if ok {
+			found = append(found, p)
+		}
+
+		pipeline, isPipeline := p.(*MediaPipeline)
+		if !isPipeline {
+			continue
+		}
+		children, err := pipeline.FindByTag(ctx, key, value)
+		if isGone(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		found = append(found, children...)
+	}
+	return found, nil
+}
+
+// Tell if an error is the media server not finding the object, e.g. because it
+// was released meanwhile.
+func isGone(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == ObjectNotFound
+}
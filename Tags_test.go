@@ -0,0 +1,137 @@
+package kurento
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestIsGone(t *testing.T) {
+	tests := []struct {
+		err  error
+		gone bool
+	}{
+		{nil, false},
+		{errors.New("kurento: other"), false},
+		{&Error{Code: ObjectNotFound, Message: "Object not found"}, true},
+		{fmt.Errorf("kurento: release: %w", &Error{Code: ObjectNotFound}), true},
+		{&Error{Code: ConnectionLost}, false},
+		{&Error{Code: NotCreated}, false},
+		{&Error{Code: -32602, Message: "Invalid params"}, false},
+		{&Error{Code: 40001, Message: "Unexpected error"}, false},
+	}
+	for _, tt := range tests {
+		if got := isGone(tt.err); got != tt.gone {
+			t.Errorf("isGone(%v) = %v, want %v", tt.err, got, tt.gone)
+		}
+	}
+}
+
+func TestGetTags(t *testing.T) {
+	k := newFakeKms(t)
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err := pipeline.GetTags()
+	if err != nil || len(tags) != 0 || tags == nil {
+		t.Errorf("GetTags() without tags = %#v, %v", tags, err)
+	}
+
+	if err := pipeline.SetTags(ctx, map[string]string{"room": "lobby"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pipeline.AddTag("empty", ""); err != nil {
+		t.Fatal(err)
+	}
+	want := []Tag{{"room", "lobby"}, {"empty", ""}}
+	if tags, err := pipeline.GetTags(); err != nil || !reflect.DeepEqual(tags, want) {
+		t.Errorf("GetTags() = %v, %v, want %v", tags, err, want)
+	}
+	if tags, err := pipeline.getTagsContext(ctx); err != nil || !reflect.DeepEqual(tags, want) {
+		t.Errorf("getTagsContext() = %v, %v, want %v", tags, err, want)
+	}
+	if m := TagMap(want); !reflect.DeepEqual(m, map[string]string{"room": "lobby", "empty": ""}) {
+		t.Errorf("TagMap() = %v", m)
+	}
+}
+
+func TestFindByTag(t *testing.T) {
+	k := newFakeKms(t)
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lobby, err := New[*WebRtcEndpoint](ctx, pipeline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub, err := New[*Composite](ctx, pipeline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := New[*HubPort](ctx, hub, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stage, err := New[*WebRtcEndpoint](ctx, other, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for m, room := range map[*MediaObject]string{
+		&lobby.MediaObject: "lobby",
+		&port.MediaObject:  "lobby",
+		&hub.MediaObject:   "stage",
+		&other.MediaObject: "stage",
+		&stage.MediaObject: "stage",
+	} {
+		if err := m.SetTags(ctx, map[string]string{"room": room}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ids := func(objects []IMediaObject) []ObjectID {
+		ret := []ObjectID{}
+		for _, m := range objects {
+			ret = append(ret, m.mediaObject().Id)
+		}
+		return ret
+	}
+	tests := []struct {
+		key, value string
+		want       []ObjectID
+	}{
+		{"room", "lobby", []ObjectID{lobby.Id, port.Id}},
+		{"room", "stage", []ObjectID{hub.Id}},
+		{"room", "", []ObjectID{lobby.Id, hub.Id, port.Id}},
+		{"tenant", "", []ObjectID{}},
+	}
+	for _, tt := range tests {
+		found, err := pipeline.FindByTag(ctx, tt.key, tt.value)
+		if err != nil || !reflect.DeepEqual(ids(found), tt.want) {
+			t.Errorf("FindByTag(%q, %q) = %v, %v, want %v", tt.key, tt.value, ids(found), err, tt.want)
+		}
+	}
+
+	k.invoke = func(object, operation string, params map[string]interface{}) (interface{}, *Error) {
+		return []string{string(pipeline.Id), string(other.Id)}, nil
+	}
+	manager := new(ServerManager)
+	manager.setConnection(k.conn)
+	manager.setId(serverManagerId)
+	found, err := manager.FindByTag(ctx, "room", "stage")
+	if want := []ObjectID{hub.Id, other.Id, stage.Id}; err != nil || !reflect.DeepEqual(ids(found), want) {
+		t.Errorf("ServerManager.FindByTag() = %v, %v, want %v", ids(found), err, want)
+	}
+	if _, isHub := found[0].(*Composite); !isHub {
+		t.Errorf("found a %T", found[0])
+	}
+}
@@ -0,0 +1,146 @@
+package kurento
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Topology is the logical graph of a pipeline: its elements and hubs, and the
+// connections between them by media type. It can be rendered as Graphviz DOT,
+// or encoded to JSON.
+type Topology struct {
+	Pipeline ObjectID       `json:"pipeline"`
+	Nodes    []TopologyNode `json:"nodes"`
+
+	// Connections, one per media type
+	Edges []ConnectionSnapshot `json:"edges"`
+}
+
+// TopologyNode is an element or a hub of the pipeline.
+type TopologyNode struct {
+	Id   ObjectID `json:"id"`
+	Type string   `json:"type"`
+
+	// Hub of a HubPort
+	Hub ObjectID `json:"hub,omitempty"`
+}
+
+// Topology queries the elements of the pipeline and their connections.
+func (elem *MediaPipeline) Topology(ctx context.Context) (*Topology, error) {
+	objects, err := elem.walk(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Topology{
+		Pipeline: elem.Id,
+		Nodes:    []TopologyNode{},
+		Edges:    []ConnectionSnapshot{},
+	}
+	for _, o := range objects {
+		id := o.object.mediaObject().Id
+		node := TopologyNode{Id: id, Type: canonicalTypeName(id.Type())}
+		if _, isPort := o.object.(IHubPort); isPort {
+			node.Hub = o.parent
+		}
+		t.Nodes = append(t.Nodes, node)
+
+		edges, err := sinkConnections(ctx, o.object)
+		if err != nil {
+			return nil, err
+		}
+		t.Edges = append(t.Edges, edges...)
+	}
+	return t, nil
+}
+
+// Topology returns the graph of a snapshot.
+func (snap *PipelineSnapshot) Topology() *Topology {
+	t := &Topology{
+		Pipeline: snap.Pipeline,
+		Nodes:    make([]TopologyNode, 0, len(snap.Elements)),
+		Edges:    snap.Connections,
+	}
+	for _, e := range snap.Elements {
+		t.Nodes = append(t.Nodes, TopologyNode{Id: e.Id, Type: e.Type, Hub: e.Hub})
+	}
+	return t
+}
+
+// Node returns the node with the given ID, nil if there is none.
+func (t *Topology) Node(id ObjectID) *TopologyNode {
+	for i := range t.Nodes {
+		if t.Nodes[i].Id == id {
+			return &t.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// Colors of the edges by media type.
+var topologyColors = map[MediaType]string{
+	MEDIATYPE_AUDIO: "blue",
+	MEDIATYPE_VIDEO: "red",
+	MEDIATYPE_DATA:  "darkgreen",
+}
+
+// DOT renders the graph in Graphviz format. Hubs are drawn as ellipses linked
+// to their ports by dashed lines, connections are labelled and colored by
+// media type.
+func (t *Topology) DOT() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "digraph %s {\n", strconv.Quote(string(t.Pipeline)))
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box, fontname=\"sans\"];\n")
+	b.WriteString("\tedge [fontname=\"sans\", fontsize=9];\n")
+
+	for _, n := range t.Nodes {
+		shape := "box"
+		if isHubType(n.Type) {
+			shape = "ellipse"
+		}
+		label := n.Type + "\n" + shortUUID(n.Id.UUID())
+		fmt.Fprintf(&b, "\t%s [label=%s, shape=%s];\n", strconv.Quote(string(n.Id)), strconv.Quote(label), shape)
+	}
+	for _, n := range t.Nodes {
+		if n.Hub != "" {
+			fmt.Fprintf(&b, "\t%s -> %s [style=dashed, arrowhead=none];\n", strconv.Quote(string(n.Hub)), strconv.Quote(string(n.Id)))
+		}
+	}
+	for _, e := range t.Edges {
+		color, ok := topologyColors[e.Type]
+		if !ok {
+			color = "black"
+		}
+		fmt.Fprintf(&b, "\t%s -> %s [label=%s, color=%s];\n",
+			strconv.Quote(string(e.Source)), strconv.Quote(string(e.Sink)), strconv.Quote(string(e.Type)), color)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// JSON encodes the graph.
+func (t *Topology) JSON() ([]byte, error) {
+	return json.MarshalIndent(t, "", "  ")
+}
+
+// Tell if a registered type is a hub.
+func isHubType(typeName string) bool {
+	m, err := NewObject(typeName)
+	if err != nil {
+		return false
+	}
+	_, ok := m.(IHub)
+	return ok
+}
+
+// Short form of an UUID for labels.
+func shortUUID(uuid string) string {
+	if len(uuid) > 8 {
+		return uuid[:8]
+	}
+	return uuid
+}
@@ -0,0 +1,123 @@
+package kurento
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestTopologyHubPorts(t *testing.T) {
+	k := newFakeKms(t)
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := New[*WebRtcEndpoint](ctx, pipeline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// created by another client
+	hub := ObjectID(k.add("Composite", string(pipeline.Id)))
+	port := ObjectID(k.add("HubPort", string(hub)))
+	if err := ep.Connect(decodeObjectRef(k.conn, string(port)).(IMediaElement), "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	topology, err := pipeline.Topology(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Topology{
+		Pipeline: pipeline.Id,
+		Nodes: []TopologyNode{
+			{Id: ep.Id, Type: "WebRtcEndpoint"},
+			{Id: hub, Type: "Composite"},
+			{Id: port, Type: "HubPort", Hub: hub},
+		},
+		Edges: []ConnectionSnapshot{
+			{Source: ep.Id, Sink: port, Type: MEDIATYPE_AUDIO},
+			{Source: ep.Id, Sink: port, Type: MEDIATYPE_VIDEO},
+		},
+	}
+	if !reflect.DeepEqual(topology, want) {
+		t.Errorf("topology %+v, want %+v", topology, want)
+	}
+	if findings := LintTopology(topology); len(findings) != 0 {
+		t.Errorf("findings %+v", findings)
+	}
+}
+
+func testTopology() *Topology {
+	const pipeline = "p_kurento.MediaPipeline"
+	return &Topology{
+		Pipeline: pipeline,
+		Nodes: []TopologyNode{
+			{Id: pipeline + "/0123456789_kurento.WebRtcEndpoint", Type: "WebRtcEndpoint"},
+			{Id: pipeline + "/h_kurento.Composite", Type: "Composite"},
+			{Id: pipeline + "/p_kurento.HubPort", Type: "HubPort", Hub: pipeline + "/h_kurento.Composite"},
+		},
+		Edges: []ConnectionSnapshot{
+			{Source: pipeline + "/0123456789_kurento.WebRtcEndpoint", Sink: pipeline + "/p_kurento.HubPort", Type: MEDIATYPE_AUDIO},
+			{Source: pipeline + "/p_kurento.HubPort", Sink: pipeline + "/0123456789_kurento.WebRtcEndpoint", Type: MEDIATYPE_DATA},
+		},
+	}
+}
+
+func TestTopologyDOT(t *testing.T) {
+	want := `digraph "p_kurento.MediaPipeline" {
+	rankdir=LR;
+	node [shape=box, fontname="sans"];
+	edge [fontname="sans", fontsize=9];
+	"p_kurento.MediaPipeline/0123456789_kurento.WebRtcEndpoint" [label="WebRtcEndpoint\n01234567", shape=box];
+	"p_kurento.MediaPipeline/h_kurento.Composite" [label="Composite\nh", shape=ellipse];
+	"p_kurento.MediaPipeline/p_kurento.HubPort" [label="HubPort\np", shape=box];
+	"p_kurento.MediaPipeline/h_kurento.Composite" -> "p_kurento.MediaPipeline/p_kurento.HubPort" [style=dashed, arrowhead=none];
+	"p_kurento.MediaPipeline/0123456789_kurento.WebRtcEndpoint" -> "p_kurento.MediaPipeline/p_kurento.HubPort" [label="AUDIO", color=blue];
+	"p_kurento.MediaPipeline/p_kurento.HubPort" -> "p_kurento.MediaPipeline/0123456789_kurento.WebRtcEndpoint" [label="DATA", color=darkgreen];
+}
+`
+	if got := testTopology().DOT(); got != want {
+		t.Errorf("DOT() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTopologyJSON(t *testing.T) {
+	got, err := testTopology().JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "pipeline": "p_kurento.MediaPipeline",
+  "nodes": [
+    {
+      "id": "p_kurento.MediaPipeline/0123456789_kurento.WebRtcEndpoint",
+      "type": "WebRtcEndpoint"
+    },
+    {
+      "id": "p_kurento.MediaPipeline/h_kurento.Composite",
+      "type": "Composite"
+    },
+    {
+      "id": "p_kurento.MediaPipeline/p_kurento.HubPort",
+      "type": "HubPort",
+      "hub": "p_kurento.MediaPipeline/h_kurento.Composite"
+    }
+  ],
+  "edges": [
+    {
+      "source": "p_kurento.MediaPipeline/0123456789_kurento.WebRtcEndpoint",
+      "sink": "p_kurento.MediaPipeline/p_kurento.HubPort",
+      "type": "AUDIO"
+    },
+    {
+      "source": "p_kurento.MediaPipeline/p_kurento.HubPort",
+      "sink": "p_kurento.MediaPipeline/0123456789_kurento.WebRtcEndpoint",
+      "type": "DATA"
+    }
+  ]
+}`
+	if string(got) != want {
+		t.Errorf("JSON() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,207 @@
+package kurento
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// Prefix of references to objects created earlier in the same transaction.
+const newRefPrefix = "newref:"
+
+// Transaction queues creations, invocations and releases on a MediaPipeline and
+// sends them to the media server in a single "transaction" request. Objects
+// queued for creation can be passed to later operations of the same
+// transaction, they get their identifier when the transaction is committed.
+type Transaction struct {
+	pipeline   *MediaPipeline
+	operations []map[string]interface{}
+	created    []txCreated
+	released   []IMediaObject
+}
+
+// Object queued for creation, with its parent.
+type txCreated struct {
+	index    int
+	object   IMediaObject
+	parent   *MediaObject
+	typeName string
+	options  map[string]interface{}
+}
+
+// Begin starts a transaction on the pipeline.
+func (elem *MediaPipeline) Begin() *Transaction {
+	return &Transaction{pipeline: elem}
+}
+
+// Create queues the creation of "m" in the pipeline.
+func (tx *Transaction) Create(m IMediaObject, options map[string]interface{}) error {
+	return tx.CreateFrom(tx.pipeline, m, options)
+}
+
+// CreateFrom queues the creation of "m" from "parent", that can itself be
+// queued in the transaction, e.g. a HubPort of a queued Hub.
+func (tx *Transaction) CreateFrom(parent IMediaObject, m IMediaObject, options map[string]interface{}) error {
+	typeName, err := getMediaElementType(m)
+	if err != nil {
+		return err
+	}
+	p := parent.mediaObject()
+	if err := p.checkCreated(false); err != nil {
+		return err
+	}
+
+	index := len(tx.operations)
+	tx.operations = append(tx.operations, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      index,
+		"method":  "create",
+		"params": map[string]interface{}{
+			"type":              typeName,
+			"constructorParams": constructorParams(m, p, tx.pipeline.Id, options),
+		},
+	})
+
+	m.setConnection(tx.pipeline.connection)
+	m.setId(newRefPrefix + strconv.Itoa(index))
+	tx.created = append(tx.created, txCreated{index, m, p, typeName, options})
+	return nil
+}
+
+// Invoke queues the call of "operation" on "m". The params are sent as given,
+// zero values included, except media objects that are sent as references.
+func (tx *Transaction) Invoke(m IMediaObject, operation string, params map[string]interface{}) error {
+	if err := m.mediaObject().checkCreated(false); err != nil {
+		return err
+	}
+
+	opparams := make(map[string]interface{})
+	for name, value := range params {
+		switch v := value.(type) {
+		case IMediaObject:
+			opparams[name] = v.String()
+		case ICustomSerializer:
+			opparams[name] = v.CustomSerialize()
+		default:
+			opparams[name] = value
+		}
+	}
+
+	reqparams := map[string]interface{}{
+		"operation": operation,
+		"object":    m.String(),
+	}
+	if len(opparams) > 0 {
+		reqparams["operationParams"] = opparams
+	}
+	tx.operations = append(tx.operations, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      len(tx.operations),
+		"method":  "invoke",
+		"params":  reqparams,
+	})
+	return nil
+}
+
+// Connect queues the connection of "source" to "sink" for the given media type,
+// all media types if empty.
+func (tx *Transaction) Connect(source, sink IMediaElement, mediaType MediaType) error {
+	params := map[string]interface{}{"sink": sink}
+	if mediaType != "" {
+		params["mediaType"] = mediaType
+	}
+	return tx.Invoke(source, "connect", params)
+}
+
+// Release queues the release of "m".
+func (tx *Transaction) Release(m IMediaObject) error {
+	if err := m.mediaObject().checkCreated(false); err != nil {
+		return err
+	}
+	tx.operations = append(tx.operations, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      len(tx.operations),
+		"method":  "release",
+		"params": map[string]interface{}{
+			"object": m.String(),
+		},
+	})
+	tx.released = append(tx.released, m)
+	return nil
+}
+
+// Commit sends the queued operations in one request and sets the identifiers
+// of the created objects. If the transaction fails, the objects queued for
+// creation are left not created. The transaction is empty afterwards and can
+// be reused.
+func (tx *Transaction) Commit(ctx context.Context) error {
+	operations, created, released := tx.operations, tx.created, tx.released
+	tx.operations, tx.created, tx.released = nil, nil, nil
+	if len(operations) == 0 {
+		return nil
+	}
+
+	req := tx.pipeline.getCreateRequest()
+	req["method"] = "transaction"
+	req["params"] = map[string]interface{}{
+		"operations": operations,
+	}
+
+	res, err := waitResponse(ctx, tx.pipeline.request(req))
+	if debug {
+		log.Println("Transaction response: ", res)
+	}
+	if err == nil {
+		err = tx.resolve(res, operations, created)
+	}
+	if err != nil {
+		for _, c := range created {
+			c.object.setId("")
+		}
+		return err
+	}
+	for _, m := range released {
+		tx.pipeline.connection.recordReleased(m.mediaObject().Id)
+	}
+	return nil
+}
+
+// Check the result of each operation and set the created objects ID.
+func (tx *Transaction) resolve(res Response, operations []map[string]interface{}, created []txCreated) error {
+	var results []struct {
+		Value  json.RawMessage
+		Result struct {
+			Value json.RawMessage
+		}
+		Error *Error
+	}
+	if err := res.decodeValue(&results); err != nil {
+		return err
+	}
+	if len(results) != len(operations) {
+		return fmt.Errorf("kurento: transaction returned %d results for %d operations", len(results), len(operations))
+	}
+	for i, r := range results {
+		if r.Error != nil {
+			return fmt.Errorf("kurento: transaction operation %d (%s) failed: %w", i, operations[i]["method"], r.Error)
+		}
+	}
+
+	for _, c := range created {
+		r := results[c.index]
+		value := r.Result.Value
+		if len(value) == 0 {
+			value = r.Value
+		}
+		var id string
+		if err := json.Unmarshal(value, &id); err != nil || id == "" {
+			return fmt.Errorf("kurento: transaction operation %d returned no object id", c.index)
+		}
+		c.object.setId(id)
+		c.parent.addChild(c.object)
+		tx.pipeline.connection.recordCreated(ObjectID(id), c.typeName, c.parent.Id, c.options)
+	}
+	return nil
+}
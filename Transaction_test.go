@@ -0,0 +1,97 @@
+package kurento
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransactionCommit(t *testing.T) {
+	k := newFakeKms(t)
+	k.invoke = func(object, operation string, params map[string]interface{}) (interface{}, *Error) {
+		return "ok", nil
+	}
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := pipeline.Begin()
+	source, sink := new(WebRtcEndpoint), new(RtpEndpoint)
+	hub, port := new(Composite), new(HubPort)
+	for _, err := range []error{
+		tx.Create(source, nil),
+		tx.Create(sink, nil),
+		tx.Connect(source, sink, ""),
+		tx.Connect(source, sink, MEDIATYPE_VIDEO),
+		tx.Invoke(sink, "setMaxVideoSendBandwidth", map[string]interface{}{"value": 0}),
+		tx.Invoke(sink, "setConfig", map[string]interface{}{"enabled": false, "name": "", "limits": []int{1, 2}}),
+		tx.Create(hub, nil),
+		tx.CreateFrom(hub, port, nil),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := jsonValue(t, `[
+		{"jsonrpc": "2.0", "id": 0, "method": "create", "params": {"type": "WebRtcEndpoint", "constructorParams": {"mediaPipeline": "`+string(pipeline.Id)+`", "useDataChannels": false}}},
+		{"jsonrpc": "2.0", "id": 1, "method": "create", "params": {"type": "RtpEndpoint", "constructorParams": {"mediaPipeline": "`+string(pipeline.Id)+`"}}},
+		{"jsonrpc": "2.0", "id": 2, "method": "invoke", "params": {"object": "newref:0", "operation": "connect", "operationParams": {"sink": "newref:1"}}},
+		{"jsonrpc": "2.0", "id": 3, "method": "invoke", "params": {"object": "newref:0", "operation": "connect", "operationParams": {"sink": "newref:1", "mediaType": "VIDEO"}}},
+		{"jsonrpc": "2.0", "id": 4, "method": "invoke", "params": {"object": "newref:1", "operation": "setMaxVideoSendBandwidth", "operationParams": {"value": 0}}},
+		{"jsonrpc": "2.0", "id": 5, "method": "invoke", "params": {"object": "newref:1", "operation": "setConfig", "operationParams": {"enabled": false, "name": "", "limits": [1, 2]}}},
+		{"jsonrpc": "2.0", "id": 6, "method": "create", "params": {"type": "Composite", "constructorParams": {"mediaPipeline": "`+string(pipeline.Id)+`"}}},
+		{"jsonrpc": "2.0", "id": 7, "method": "create", "params": {"type": "HubPort", "constructorParams": {"hub": "newref:6"}}}
+	]`)
+	if got := k.requests("transaction")[0].Params["operations"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("operations %v, want %v", got, want)
+	}
+
+	// each created object gets the ID returned by its own operation
+	ids := k.ids()
+	if len(ids) != 5 {
+		t.Fatalf("objects %v", ids)
+	}
+	for i, m := range []IMediaObject{pipeline, source, sink, hub, port} {
+		if got := m.mediaObject().Id; string(got) != ids[i] {
+			t.Errorf("object %d has id %s, want %s", i, got, ids[i])
+		}
+	}
+	if r, ok := k.conn.createdRecord(port.Id); !ok || r.from != hub.Id || r.typeName != "HubPort" {
+		t.Errorf("port created record %+v, %v", r, ok)
+	}
+}
+
+func TestTransactionFailure(t *testing.T) {
+	k := newFakeKms(t)
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx := pipeline.Begin()
+	ep := new(WebRtcEndpoint)
+	if err := tx.Create(ep, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Invoke(ep, "unknownOperation", nil); err != nil {
+		t.Fatal(err)
+	}
+	err = tx.Commit(ctx)
+	if err == nil || !strings.Contains(err.Error(), "operation 1 (invoke)") {
+		t.Errorf("Commit error %v", err)
+	}
+	if ep.Id != "" {
+		t.Errorf("object of a failed transaction has id %s", ep.Id)
+	}
+	if err := tx.Commit(ctx); err != nil || len(k.requests("transaction")) != 1 {
+		t.Errorf("empty transaction sent, error %v", err)
+	}
+}
@@ -0,0 +1,252 @@
+package kurento
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Events of WebRtcEndpoint ICE gathering.
+const (
+	EventOnIceCandidate     = "OnIceCandidate"
+	EventOnIceGatheringDone = "OnIceGatheringDone"
+)
+
+// TrickleICE helps doing trickle ICE with a WebRtcEndpoint. Local candidates
+// are delivered on the Candidates channel until the helper is closed. Each
+// negotiation, e.g. an ICE restart, starts a gathering round whose end is
+// signaled by GatheringDone. Remote candidates given to AddIceCandidate are
+// buffered until the first negotiation has been processed by ProcessOffer or
+// ProcessAnswer.
+type TrickleICE struct {
+	ep *WebRtcEndpoint
+
+	candidates chan IceCandidate
+	stop       chan struct{}
+
+	mu         sync.Mutex
+	queue      []trickleItem // local candidates and round ends not yet delivered
+	done       chan struct{} // end of the current round
+	gathering  bool          // gathering was started in the current round
+	gathered   bool          // the current round is done
+	negotiated bool
+	pending    []IceCandidate // remote candidates waiting for negotiation
+	subs       []*Subscription
+	wake       chan struct{}
+	stopOnce   sync.Once
+}
+
+// A local candidate, or the end of a gathering round.
+type trickleItem struct {
+	candidate IceCandidate
+	done      chan struct{}
+}
+
+// Trickle subscribes to the ICE events of the endpoint. It must be called
+// before the SDP negotiation.
+func (elem *WebRtcEndpoint) Trickle(ctx context.Context) (*TrickleICE, error) {
+	t := &TrickleICE{
+		ep:         elem,
+		candidates: make(chan IceCandidate),
+		done:       make(chan struct{}),
+		stop:       make(chan struct{}),
+		wake:       make(chan struct{}, 1),
+	}
+
+	sub, err := elem.subscribeContext(ctx, EventOnIceCandidate, t.onCandidate)
+	if err != nil {
+		return nil, err
+	}
+	t.subs = append(t.subs, sub)
+
+	sub, err = elem.subscribeContext(ctx, EventOnIceGatheringDone, t.onGatheringDone)
+	if err != nil {
+		t.subs[0].Close(ctx)
+		return nil, err
+	}
+	t.subs = append(t.subs, sub)
+
+	go t.pump()
+	return t, nil
+}
+
+// Candidates returns the local candidates to forward to the remote peer, of
+// every gathering round. The channel is closed when the helper is closed.
+func (t *TrickleICE) Candidates() <-chan IceCandidate {
+	return t.candidates
+}
+
+// GatheringDone returns a channel closed once the candidates of the current
+// gathering round were delivered on Candidates.
+func (t *TrickleICE) GatheringDone() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.done
+}
+
+// GenerateOffer generates an SDP offer and starts gathering, so that local
+// candidates can be forwarded with the offer. Remote candidates are buffered
+// until the answer is given to ProcessAnswer.
+func (t *TrickleICE) GenerateOffer() (string, error) {
+	offer, err := t.ep.GenerateOffer()
+	if err != nil {
+		return offer, err
+	}
+	t.newRound()
+	return offer, t.gather()
+}
+
+// ProcessOffer processes the remote offer, adds the buffered remote
+// candidates and starts gathering. It returns the SDP answer.
+func (t *TrickleICE) ProcessOffer(offer string) (string, error) {
+	answer, err := t.ep.ProcessOffer(offer)
+	if err != nil {
+		return answer, err
+	}
+	t.newRound()
+	if err := t.negotiationDone(); err != nil {
+		return answer, err
+	}
+	return answer, t.gather()
+}
+
+// ProcessAnswer processes the remote answer and adds the buffered remote
+// candidates. Gathering was started by GenerateOffer.
+func (t *TrickleICE) ProcessAnswer(answer string) (string, error) {
+	ret, err := t.ep.ProcessAnswer(answer)
+	if err != nil {
+		return ret, err
+	}
+	if err := t.negotiationDone(); err != nil {
+		return ret, err
+	}
+	// the offer may not have been generated through the helper
+	return ret, t.gather()
+}
+
+// AddIceCandidate adds a remote candidate, or buffers it until the
+// negotiation has been processed.
+func (t *TrickleICE) AddIceCandidate(candidate IceCandidate) error {
+	t.mu.Lock()
+	if !t.negotiated {
+		t.pending = append(t.pending, candidate)
+		t.mu.Unlock()
+		return nil
+	}
+	t.mu.Unlock()
+	return t.ep.AddIceCandidate(candidate)
+}
+
+// Close unsubscribes from the ICE events and closes the Candidates channel.
+func (t *TrickleICE) Close(ctx context.Context) error {
+	var errs []error
+	for _, sub := range t.subs {
+		errs = append(errs, sub.Close(ctx))
+	}
+	t.stopOnce.Do(func() { close(t.stop) })
+	return errors.Join(errs...)
+}
+
+// Start a gathering round, unless the current one is not done yet.
+func (t *TrickleICE) newRound() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.gathered {
+		t.done = make(chan struct{})
+		t.gathering, t.gathered = false, false
+	}
+}
+
+// Start gathering, once per round.
+func (t *TrickleICE) gather() error {
+	t.mu.Lock()
+	started := t.gathering
+	t.gathering = true
+	t.mu.Unlock()
+	if started {
+		return nil
+	}
+	return t.ep.GatherCandidates()
+}
+
+// Flush buffered remote candidates.
+func (t *TrickleICE) negotiationDone() error {
+	t.mu.Lock()
+	t.negotiated = true
+	pending := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+
+	for _, c := range pending {
+		if err := t.ep.AddIceCandidate(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Event handlers run in the connection reader and must not block.
+func (t *TrickleICE) onCandidate(data map[string]interface{}) {
+	raw, ok := data["candidate"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	c := IceCandidate{}
+	c.Candidate, _ = raw["candidate"].(string)
+	c.SdpMid, _ = raw["sdpMid"].(string)
+	if index, ok := raw["sdpMLineIndex"].(float64); ok {
+		c.SdpMLineIndex = int(index)
+	}
+
+	t.mu.Lock()
+	t.queue = append(t.queue, trickleItem{candidate: c})
+	t.mu.Unlock()
+	t.signal()
+}
+
+func (t *TrickleICE) onGatheringDone(map[string]interface{}) {
+	t.mu.Lock()
+	if !t.gathered {
+		t.gathered = true
+		t.queue = append(t.queue, trickleItem{done: t.done})
+	}
+	t.mu.Unlock()
+	t.signal()
+}
+
+func (t *TrickleICE) signal() {
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Deliver local candidates in order, closing the done channel of a round
+// after its candidates, until the helper is closed.
+func (t *TrickleICE) pump() {
+	defer close(t.candidates)
+	for {
+		t.mu.Lock()
+		queue := t.queue
+		t.queue = nil
+		t.mu.Unlock()
+
+		for _, item := range queue {
+			if item.done != nil {
+				close(item.done)
+				continue
+			}
+			select {
+			case t.candidates <- item.candidate:
+			case <-t.stop:
+				return
+			}
+		}
+
+		select {
+		case <-t.wake:
+		case <-t.stop:
+			return
+		}
+	}
+}
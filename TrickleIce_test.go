@@ -0,0 +1,178 @@
+package kurento
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Return a trickle helper on an endpoint of the fake media server, answering
+// the SDP and ICE operations.
+func testTrickle(t *testing.T) (*fakeKms, *WebRtcEndpoint, *TrickleICE) {
+	k := newFakeKms(t)
+	k.invoke = func(object, operation string, params map[string]interface{}) (interface{}, *Error) {
+		switch operation {
+		case "generateOffer", "processOffer", "processAnswer":
+			return "v=0", nil
+		}
+		return nil, nil
+	}
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := New[*WebRtcEndpoint](ctx, pipeline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trickle, err := ep.Trickle(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { trickle.Close(ctx) })
+	return k, ep, trickle
+}
+
+func sendCandidate(k *fakeKms, ep *WebRtcEndpoint, c IceCandidate) {
+	k.event(EventOnIceCandidate, string(ep.Id), map[string]interface{}{
+		"candidate": map[string]interface{}{
+			"candidate":     c.Candidate,
+			"sdpMid":        c.SdpMid,
+			"sdpMLineIndex": c.SdpMLineIndex,
+		},
+	})
+}
+
+func receiveCandidates(t *testing.T, trickle *TrickleICE, n int) []IceCandidate {
+	t.Helper()
+	var ret []IceCandidate
+	for len(ret) < n {
+		select {
+		case c, ok := <-trickle.Candidates():
+			if !ok {
+				t.Fatal("candidates channel closed")
+			}
+			ret = append(ret, c)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d candidates, want %d", len(ret), n)
+		}
+	}
+	return ret
+}
+
+func waitClosed(t *testing.T, ch <-chan struct{}, name string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("%s not closed", name)
+	}
+}
+
+// Return the ICE and SDP operations invoked on the endpoint.
+func trickleOperations(k *fakeKms) []string {
+	ret := []string{}
+	for _, c := range k.requests("invoke") {
+		ret = append(ret, c.Params["operation"].(string))
+	}
+	return ret
+}
+
+func TestTrickleAnswerer(t *testing.T) {
+	k, ep, trickle := testTrickle(t)
+	remote := IceCandidate{Candidate: "candidate:1 1 udp 2122260223 192.168.1.10 54321 typ host", SdpMid: "0"}
+	if err := trickle.AddIceCandidate(remote); err != nil {
+		t.Fatal(err)
+	}
+	if ops := trickleOperations(k); len(ops) != 0 {
+		t.Errorf("remote candidate added before negotiation: %v", ops)
+	}
+	if _, err := trickle.ProcessOffer("v=0"); err != nil {
+		t.Fatal(err)
+	}
+	if ops, want := trickleOperations(k), []string{"processOffer", "addIceCandidate", "gatherCandidates"}; !reflect.DeepEqual(ops, want) {
+		t.Errorf("operations %v, want %v", ops, want)
+	}
+
+	// candidates are buffered until read, the round ends after them
+	local := []IceCandidate{
+		{Candidate: "candidate:1 1 udp 2122260223 10.0.0.2 40000 typ host", SdpMid: "0"},
+		{Candidate: "candidate:2 1 udp 1677729535 203.0.113.7 40001 typ srflx raddr 10.0.0.2 rport 40000", SdpMid: "1", SdpMLineIndex: 1},
+	}
+	first := trickle.GatheringDone()
+	for _, c := range local {
+		sendCandidate(k, ep, c)
+	}
+	k.event(EventOnIceGatheringDone, string(ep.Id), nil)
+	select {
+	case <-first:
+		t.Fatal("round done before its candidates were read")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if got := receiveCandidates(t, trickle, 2); !reflect.DeepEqual(got, local) {
+		t.Errorf("candidates %v, want %v", got, local)
+	}
+	waitClosed(t, first, "first round")
+
+	// an ICE restart starts another round on the same channel
+	if _, err := trickle.ProcessOffer("v=0"); err != nil {
+		t.Fatal(err)
+	}
+	second := trickle.GatheringDone()
+	if second == first {
+		t.Fatal("no round started by the restart")
+	}
+	sendCandidate(k, ep, local[0])
+	k.event(EventOnIceGatheringDone, string(ep.Id), nil)
+	if got := receiveCandidates(t, trickle, 1); got[0] != local[0] {
+		t.Errorf("restart candidate %v", got[0])
+	}
+	waitClosed(t, second, "second round")
+	if ops := trickleOperations(k); ops[len(ops)-1] != "gatherCandidates" || len(ops) != 5 {
+		t.Errorf("operations %v", ops)
+	}
+
+	if err := trickle.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case _, ok := <-trickle.Candidates():
+		if ok {
+			t.Error("candidate received after Close")
+		}
+	case <-time.After(time.Second):
+		t.Error("candidates channel not closed")
+	}
+}
+
+func TestTrickleOfferer(t *testing.T) {
+	k, ep, trickle := testTrickle(t)
+	if _, err := trickle.GenerateOffer(); err != nil {
+		t.Fatal(err)
+	}
+
+	// local candidates are forwarded before the answer
+	local := IceCandidate{Candidate: "candidate:1 1 udp 2122260223 10.0.0.2 40000 typ host", SdpMid: "0"}
+	sendCandidate(k, ep, local)
+	if got := receiveCandidates(t, trickle, 1); got[0] != local {
+		t.Errorf("candidate %v, want %v", got[0], local)
+	}
+
+	// remote candidates wait for the answer
+	remote := IceCandidate{Candidate: "candidate:1 1 udp 2122260223 192.168.1.10 54321 typ host", SdpMid: "0"}
+	if err := trickle.AddIceCandidate(remote); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := trickle.ProcessAnswer("v=0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := trickle.AddIceCandidate(remote); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"generateOffer", "gatherCandidates", "processAnswer", "addIceCandidate", "addIceCandidate"}
+	if ops := trickleOperations(k); !reflect.DeepEqual(ops, want) {
+		t.Errorf("operations %v, want %v", ops, want)
+	}
+}
@@ -0,0 +1,128 @@
+package kurento
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// DefaultUploadChunkSize is the size of the chunks sent by an Uploader.
+const DefaultUploadChunkSize = 32 * 1024
+
+// Uploader streams media to the URL of an HttpPostEndpoint, with chunked
+// transfer encoding. The zero value is ready to use.
+type Uploader struct {
+	// http.DefaultClient if nil
+	Client *http.Client
+
+	// DefaultUploadChunkSize if 0
+	ChunkSize int
+
+	// Content-Type header, e.g. "video/webm", if set
+	ContentType string
+
+	// Called after each chunk with the number of bytes read from the source
+	// so far, if set. They are handed to the HTTP transport, which may still
+	// buffer them.
+	Progress func(read int64)
+}
+
+// Upload posts the content of "r" to "url" until EOF, and returns the number
+// of bytes read from "r", that were all sent if there is no error. Cancelling
+// the context aborts the upload.
+func (u *Uploader) Upload(ctx context.Context, url string, r io.Reader) (int64, error) {
+	body := &uploadReader{r: r, chunkSize: u.ChunkSize, progress: u.Progress}
+	if body.chunkSize <= 0 {
+		body.chunkSize = DefaultUploadChunkSize
+	}
+
+	// unknown length, so that the body is sent chunked
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return 0, err
+	}
+	req.ContentLength = -1
+	if u.ContentType != "" {
+		req.Header.Set("Content-Type", u.ContentType)
+	}
+
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return body.sent.Load(), err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return body.sent.Load(), fmt.Errorf("kurento: upload to %s failed: %s", url, res.Status)
+	}
+	return body.sent.Load(), nil
+}
+
+// Upload streams the content of "r" to the endpoint with "u", or a default
+// Uploader if nil, then waits for the EndOfStream event, once the media server
+// processed the whole stream. It returns the number of bytes sent. Use a
+// context deadline to bound the wait.
+func (elem *HttpPostEndpoint) Upload(ctx context.Context, r io.Reader, u *Uploader) (int64, error) {
+	if u == nil {
+		u = new(Uploader)
+	}
+
+	// subscribe first, so that the event is not missed; the handler runs in
+	// the connection reader and must not block
+	eos := make(chan struct{}, 1)
+	sub, err := elem.subscribeContext(ctx, EventEndOfStream, func(map[string]interface{}) {
+		select {
+		case eos <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer sub.Close(context.WithoutCancel(ctx))
+
+	url, err := elem.GetUrl()
+	if err != nil {
+		return 0, err
+	}
+	sent, err := u.Upload(ctx, url, r)
+	if err != nil {
+		return sent, err
+	}
+
+	select {
+	case <-eos:
+		return sent, nil
+	case <-ctx.Done():
+		return sent, ctx.Err()
+	}
+}
+
+// Read the source at most a chunk at a time, reporting progress.
+type uploadReader struct {
+	r         io.Reader
+	chunkSize int
+	progress  func(read int64)
+	sent      atomic.Int64 // read by Upload while the transport may still read
+}
+
+func (u *uploadReader) Read(p []byte) (int, error) {
+	if len(p) > u.chunkSize {
+		p = p[:u.chunkSize]
+	}
+	n, err := u.r.Read(p)
+	if n > 0 {
+		sent := u.sent.Add(int64(n))
+		if u.progress != nil {
+			u.progress(sent)
+		}
+	}
+	return n, err
+}
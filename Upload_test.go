@@ -0,0 +1,163 @@
+package kurento
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A media server HTTP endpoint recording the uploads it receives.
+type testUploadServer struct {
+	*httptest.Server
+
+	mu               sync.Mutex
+	body             []byte
+	transferEncoding []string
+	contentType      string
+}
+
+func newTestUploadServer(t *testing.T, status int) *testUploadServer {
+	s := new(testUploadServer)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.body = body
+		s.transferEncoding = r.TransferEncoding
+		s.contentType = r.Header.Get("Content-Type")
+		s.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestUploaderChunked(t *testing.T) {
+	server := newTestUploadServer(t, http.StatusOK)
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	var progress []int64
+	u := &Uploader{
+		ChunkSize:   4096,
+		ContentType: "video/webm",
+		Progress:    func(read int64) { progress = append(progress, read) },
+	}
+
+	sent, err := u.Upload(context.Background(), server.URL, bytes.NewReader(data))
+	if err != nil || sent != int64(len(data)) {
+		t.Fatalf("Upload() = %d, %v", sent, err)
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if !bytes.Equal(server.body, data) {
+		t.Errorf("received %d bytes, want %d", len(server.body), len(data))
+	}
+	if !reflect.DeepEqual(server.transferEncoding, []string{"chunked"}) || server.contentType != "video/webm" {
+		t.Errorf("transfer encoding %v, content type %q", server.transferEncoding, server.contentType)
+	}
+	if want := []int64{4096, 8192, 10000}; !reflect.DeepEqual(progress, want) {
+		t.Errorf("progress %v, want %v", progress, want)
+	}
+}
+
+func TestUploaderStatus(t *testing.T) {
+	server := newTestUploadServer(t, http.StatusNotFound)
+	if _, err := new(Uploader).Upload(context.Background(), server.URL, bytes.NewReader([]byte("data"))); err == nil {
+		t.Error("upload rejected by the server has no error")
+	}
+}
+
+func TestUploaderCancel(t *testing.T) {
+	server := newTestUploadServer(t, http.StatusOK)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// a live source that never ends
+	r, w := io.Pipe()
+	defer w.Close()
+	go func() {
+		chunk := make([]byte, 1024)
+		for {
+			if _, err := w.Write(chunk); err != nil {
+				return
+			}
+		}
+	}()
+	u := &Uploader{Progress: func(read int64) {
+		if read > 64*1024 {
+			cancel()
+		}
+	}}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := u.Upload(ctx, server.URL, r)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Upload() error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("upload not aborted")
+	}
+}
+
+func TestHttpPostEndpointUpload(t *testing.T) {
+	server := newTestUploadServer(t, http.StatusOK)
+	k := newFakeKms(t)
+	k.invoke = func(object, operation string, params map[string]interface{}) (interface{}, *Error) {
+		return server.URL, nil
+	}
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := New[*HttpPostEndpoint](ctx, pipeline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		sent int64
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		sent, err := ep.Upload(ctx, bytes.NewReader([]byte("media")), nil)
+		done <- result{sent, err}
+	}()
+
+	// the upload is done once the media server read the whole stream
+	for len(k.requests("subscribe")) == 0 || func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return server.body == nil
+	}() {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case r := <-done:
+		t.Fatalf("Upload() = %d, %v before EndOfStream", r.sent, r.err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	k.event(EventEndOfStream, string(ep.Id), nil)
+	select {
+	case r := <-done:
+		if r.err != nil || r.sent != 5 {
+			t.Errorf("Upload() = %d, %v", r.sent, r.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Upload() did not return after EndOfStream")
+	}
+	if n := len(k.requests("unsubscribe")); n != 1 {
+		t.Errorf("%d unsubscribe requests", n)
+	}
+}
@@ -1,10 +1,42 @@
 package kurento
 
-import "fmt"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
 
 type IWebRtcEndpoint interface {
+	IBaseRtpEndpoint
 	GatherCandidates() error
 	AddIceCandidate(candidate IceCandidate) error
+	CreateDataChannel(label string, ordered bool, maxPacketLifeTime int, maxRetransmits int, protocol string) error
+	CloseDataChannel(channelId int) error
+	GetDataChannelStats(ctx context.Context) ([]RTCDataChannelStats, error)
+	RestartIce() (string, error)
+	ProcessRestartAnswer(answer string) error
+	ProcessRestartOffer(offer string) (string, error)
+	CancelRestart()
+}
+
+// Typed constructor options of a WebRtcEndpoint, use Map to pass them to
+// Create.
+type WebRtcEndpointOptions struct {
+	// Enable data channels, they must be enabled to create channels
+	UseDataChannels bool
+
+	// Key type of the DTLS certificate, "RSA" or "ECDSA"
+	CertificateKeyType string
+}
+
+// Map returns the options for Create.
+func (o WebRtcEndpointOptions) Map() map[string]interface{} {
+	ret := map[string]interface{}{
+		"useDataChannels": o.UseDataChannels,
+	}
+	setIfNotEmpty(ret, "certificateKeyType", o.CertificateKeyType)
+	return ret
 }
 
 // WebRtcEndpoint interface. This type of "Endpoint" offers media streaming using
@@ -50,13 +82,10 @@ func (elem *WebRtcEndpoint) GatherCandidates() error {
 		"operation": "gatherCandidates",
 		"object":    elem.Id,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -80,13 +109,73 @@ func (elem *WebRtcEndpoint) AddIceCandidate(candidate IceCandidate) error {
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
+	req["params"] = reqparams
+
+	// Call server and wait response
+	response := <-elem.request(req)
+
+	// Returns error or nil
+	if response.Error != nil {
+		return response.Error
+	} else {
+		return nil
+	}
+
+}
+
+// Create a new data channel, if data channels are enabled. If "ordered" is
+// false, messages can be delivered out of order. At most one of
+// "maxPacketLifeTime", in milliseconds, and "maxRetransmits" can be set to
+// limit retransmissions, -1 means unlimited. The channel is usable once the
+// DataChannelOpen event is received.
+func (elem *WebRtcEndpoint) CreateDataChannel(label string, ordered bool, maxPacketLifeTime int, maxRetransmits int, protocol string) error {
+	req := elem.getInvokeRequest()
+
+	params := map[string]interface{}{
+		"ordered":           ordered,
+		"maxPacketLifeTime": maxPacketLifeTime,
+		"maxRetransmits":    maxRetransmits,
+	}
+
+	setIfNotEmpty(params, "label", label)
+	setIfNotEmpty(params, "protocol", protocol)
+
+	reqparams := map[string]interface{}{
+		"operation":       "createDataChannel",
+		"object":          elem.Id,
+		"operationParams": params,
+	}
+	req["params"] = reqparams
+
+	// Call server and wait response
+	response := <-elem.request(req)
+
+	// Returns error or nil
+	if response.Error != nil {
+		return response.Error
+	} else {
+		return nil
+	}
+
+}
+
+// Closes the data channel identified by "channelId"
+func (elem *WebRtcEndpoint) CloseDataChannel(channelId int) error {
+	req := elem.getInvokeRequest()
+
+	params := map[string]interface{}{
+		"channelId": channelId,
+	}
+
+	reqparams := map[string]interface{}{
+		"operation":       "closeDataChannel",
+		"object":          elem.Id,
+		"operationParams": params,
 	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -96,3 +185,54 @@ func (elem *WebRtcEndpoint) AddIceCandidate(candidate IceCandidate) error {
 	}
 
 }
+
+// OnDataChannelOpen calls "cb" with the channel ID each time a data channel
+// is opened. It returns the subscription handler ID.
+func (elem *WebRtcEndpoint) OnDataChannelOpen(cb func(channelId int)) string {
+	return elem.Subscribe("DataChannelOpen", dataChannelHandler(cb))
+}
+
+// OnDataChannelClose calls "cb" with the channel ID each time a data channel
+// is closed. It returns the subscription handler ID.
+func (elem *WebRtcEndpoint) OnDataChannelClose(cb func(channelId int)) string {
+	return elem.Subscribe("DataChannelClose", dataChannelHandler(cb))
+}
+
+// Decode the channel ID of data channel events.
+func dataChannelHandler(cb func(channelId int)) eventHandler {
+	return func(data map[string]interface{}) {
+		if id, ok := data["channelId"].(float64); ok {
+			cb(int(id))
+		}
+	}
+}
+
+// GetDataChannelStats returns the statistics of the data channels of the
+// endpoint, by channel ID. The report of the DATA media type also holds
+// statistics of the endpoint and its transport, they are skipped.
+func (elem *WebRtcEndpoint) GetDataChannelStats(ctx context.Context) ([]RTCDataChannelStats, error) {
+	params := map[string]interface{}{"mediaType": MEDIATYPE_DATA}
+	response, err := elem.invoke(ctx, "getStats", params)
+	if err != nil {
+		return nil, err
+	}
+	var report map[string]json.RawMessage
+	if err := response.decodeValue(&report); err != nil {
+		return nil, err
+	}
+
+	ret := []RTCDataChannelStats{}
+	for _, raw := range report {
+		var stats Stats
+		if err := json.Unmarshal(raw, &stats); err != nil || stats.Type != STATSTYPE_datachannel {
+			continue
+		}
+		var channel RTCDataChannelStats
+		if err := json.Unmarshal(raw, &channel); err != nil {
+			return nil, err
+		}
+		ret = append(ret, channel)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Datachannelid < ret[j].Datachannelid })
+	return ret, nil
+}
@@ -0,0 +1,98 @@
+package kurento
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDataChannels(t *testing.T) {
+	k := newFakeKms(t)
+	k.invoke = func(object, operation string, params map[string]interface{}) (interface{}, *Error) {
+		return nil, nil
+	}
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := New[*WebRtcEndpoint](ctx, pipeline, map[string]interface{}{"useDataChannels": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opened := make(chan int, 1)
+	closed := make(chan int, 1)
+	ep.OnDataChannelOpen(func(id int) { opened <- id })
+	ep.OnDataChannelClose(func(id int) { closed <- id })
+
+	if err := ep.CreateDataChannel("chat", false, -1, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := ep.CloseDataChannel(3); err != nil {
+		t.Fatal(err)
+	}
+	invokes := k.requests("invoke")
+	want := []map[string]interface{}{
+		{"label": "chat", "ordered": false, "maxPacketLifeTime": -1.0, "maxRetransmits": 0.0},
+		{"channelId": 3.0},
+	}
+	for i, params := range want {
+		if got := invokes[i].Params["operationParams"]; !reflect.DeepEqual(got, params) {
+			t.Errorf("operation %s params %v, want %v", invokes[i].Params["operation"], got, params)
+		}
+	}
+
+	k.event("DataChannelOpen", string(ep.Id), map[string]interface{}{"channelId": 3})
+	k.event("DataChannelClose", string(ep.Id), map[string]interface{}{"channelId": 3})
+	for name, ch := range map[string]chan int{"open": opened, "close": closed} {
+		select {
+		case id := <-ch:
+			if id != 3 {
+				t.Errorf("%s event for channel %d", name, id)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("no %s event", name)
+		}
+	}
+}
+
+func TestGetDataChannelStats(t *testing.T) {
+	k := newFakeKms(t)
+	var params map[string]interface{}
+	k.invoke = func(object, operation string, p map[string]interface{}) (interface{}, *Error) {
+		params = p
+		return jsonValue(t, `{
+			"e1": {"id": "e1", "type": "endpoint", "timestamp": 1},
+			"t1": {"id": "t1", "type": "transport", "bytesSent": 100},
+			"d2": {"id": "d2", "type": "datachannel", "label": "files", "datachannelid": 2, "state": "closing", "messagesSent": 1},
+			"d1": {"id": "d1", "type": "datachannel", "label": "chat", "protocol": "json", "datachannelid": 1, "state": "open",
+				"messagesSent": 3, "bytesSent": 30, "messagesReceived": 2, "bytesReceived": 20}
+		}`), nil
+	}
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ep, err := New[*WebRtcEndpoint](ctx, pipeline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := ep.GetDataChannelStats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []RTCDataChannelStats{
+		{Label: "chat", Protocol: "json", Datachannelid: 1, State: "open", MessagesSent: 3, BytesSent: 30, MessagesReceived: 2, BytesReceived: 20},
+		{Label: "files", Datachannelid: 2, State: "closing", MessagesSent: 1},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("stats %+v, want %+v", stats, want)
+	}
+	if params["mediaType"] != "DATA" {
+		t.Errorf("getStats params %v", params)
+	}
+}
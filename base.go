@@ -1,10 +1,9 @@
 package kurento
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"reflect"
-	"strings"
 	"unicode"
 	"unicode/utf8"
 )
@@ -26,6 +25,18 @@ type IMediaObject interface {
 	// Those options are sent to getConstructorParams
 	Create(IMediaObject, map[string]interface{}) error
 
+	// Release the object in the media server
+	Release() error
+
+	// Subscribe to an event of the object
+	Subscribe(event string, cb eventHandler) string
+
+	// Tags management
+	AddTag(key string, value string) error
+	RemoveTag(key string) error
+	GetTag(key string) (string, error)
+	GetTags() ([]Tag, error)
+
 	// Set ID of the element
 	setId(string)
 
@@ -36,73 +47,134 @@ type IMediaObject interface {
 	addChild(IMediaObject)
 
 	setConnection(*Connection)
+
+	// Return the embedded MediaObject
+	mediaObject() *MediaObject
 }
 
 // Create object "m" with given "options"
 func (elem *MediaObject) Create(m IMediaObject, options map[string]interface{}) error {
+	return elem.createContext(context.Background(), m, options)
+}
+
+// Create object "m" with given "options", waiting for the response or for the
+// context to be done.
+func (elem *MediaObject) createContext(ctx context.Context, m IMediaObject, options map[string]interface{}) error {
+	typeName, err := getMediaElementType(m)
+	if err != nil {
+		return err
+	}
+
 	req := elem.getCreateRequest()
-	constparams := m.getConstructorParams(elem, options)
-	// TODO params["sessionId"]
+	constparams := constructorParams(m, elem, elem.Id.PipelineID(), options)
 
 	reqparams := map[string]interface{}{
-		"type":              getMediaElementType(m),
+		"type":              typeName,
 		"constructorParams": constparams,
 	}
-
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	if debug {
 		log.Printf("request to be sent: %+v\n", req)
 	}
 
-	m.setConnection(elem.connection)
-
-	res := <-elem.connection.Request(req)
+	res, err := waitResponse(ctx, elem.request(req))
 
 	if debug {
 		log.Println("Oncreate response: ", res)
 	}
-	if res.Error == nil {
-		if res.Result["value"] != "" {
-			elem.addChild(m)
-			//m.setParent(elem)
-			m.setId(res.Result["value"])
+	if err != nil {
+		return err
+	}
+
+	m.setConnection(elem.connection)
+	if res.Result["value"] != "" {
+		elem.addChild(m)
+		//m.setParent(elem)
+		m.setId(res.Result["value"])
+		elem.connection.recordCreated(ObjectID(res.Result["value"]), typeName, elem.Id, options)
+
+		// objects created from the connection itself are leased
+		if lease := elem.connection.currentLease(); lease != nil && elem.Id == "" {
+			if err := lease.Add(ctx, m); err != nil {
+				// ctx may be done, which made Add fail
+				rctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+				m.mediaObject().releaseContext(rctx)
+				cancel()
+				return err
+			}
 		}
-	} else {
-		return res.Error
 	}
 
 	return nil
 }
 
+// Return the constructor params of "m" created from "parent". Elements and
+// hubs whose type does not give the pipeline, e.g. types of other modules
+// embedding Filter, are created in "pipeline".
+func constructorParams(m IMediaObject, parent IMediaObject, pipeline ObjectID, options map[string]interface{}) map[string]interface{} {
+	params := m.getConstructorParams(parent, options)
+	_, isElement := m.(IMediaElement)
+	_, isHub := m.(IHub)
+	_, isPort := m.(IHubPort)
+	if !(isElement || isHub) || isPort || pipeline == "" {
+		return params
+	}
+	if _, ok := params["mediaPipeline"]; ok {
+		return params
+	}
+
+	// params may be the options of the caller
+	ret := copyOptions(params)
+	if ret == nil {
+		ret = make(map[string]interface{})
+	}
+	ret["mediaPipeline"] = string(pipeline)
+	return ret
+}
+
 func (elem *MediaObject) Release() error {
+	return elem.releaseContext(context.Background())
+}
+
+// Release the object, waiting for the response or for the context to be done.
+func (elem *MediaObject) releaseContext(ctx context.Context) error {
 	// Make API call to register
 	req := elem.getReleaseRequest()
 	reqparams := map[string]interface{}{
 		"object": elem.String(),
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
-	res := <-elem.connection.Request(req)
+	res, err := waitResponse(ctx, elem.request(req))
 	if debug {
 		log.Println("Release response ", res)
 	}
-
-	if res.Error != nil {
-		return res.Error
+	if err != nil {
+		return err
 	}
 
+	elem.connection.recordReleased(elem.Id)
 	return nil
 }
 
 type eventHandler func(map[string]interface{})
 
 func (elem *MediaObject) Subscribe(event string, cb eventHandler) string {
+	sub, err := elem.subscribeContext(context.Background(), event, cb)
+	if err != nil {
+		if debug {
+			log.Println("Subscribe error ", err)
+		}
+		return ""
+	}
+
+	// pass back the token so can be unregistered
+	return sub.Id
+}
+
+// Subscribe to "event", waiting for the response or for the context to be
+// done.
+func (elem *MediaObject) subscribeContext(ctx context.Context, event string, cb eventHandler) (*Subscription, error) {
 
 	// Make API call to register
 	req := elem.getSubscribeRequest()
@@ -110,13 +182,13 @@ func (elem *MediaObject) Subscribe(event string, cb eventHandler) string {
 		"type":   event,
 		"object": elem.String(),
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
-	res := <-elem.connection.Request(req)
+	res, err := waitResponse(ctx, elem.request(req))
+	if err != nil {
+		return nil, err
+	}
 
-	handlerId := res.Result["Value"]
+	handlerId := res.Result["value"]
 	if debug {
 		log.Println("Subscribe response handlerId ", handlerId)
 	}
@@ -124,8 +196,45 @@ func (elem *MediaObject) Subscribe(event string, cb eventHandler) string {
 	// tell the connection about this registered event for this mediaId event combo
 	elem.connection.Subscribe(event, elem.String(), handlerId, cb)
 
-	// pass back the token so can be unregistered
-	return handlerId
+	return &Subscription{Object: elem.Id, Event: event, Id: handlerId, connection: elem.connection}, nil
+}
+
+// Unsubscribe removes the handler "handlerId" of "event", as returned by
+// Subscribe.
+func (elem *MediaObject) Unsubscribe(event, handlerId string) error {
+	sub := &Subscription{Object: elem.Id, Event: event, Id: handlerId, connection: elem.connection}
+	return sub.Close(context.Background())
+}
+
+// Subscription is an event handler registered on a media object.
+type Subscription struct {
+	Object ObjectID
+	Event  string
+	Id     string
+
+	connection *Connection
+}
+
+// Close unregisters the handler from the media server and the connection.
+func (s *Subscription) Close(ctx context.Context) error {
+	if s.connection == nil {
+		return &Error{Code: NotCreated, Message: "Subscription is not bound to a connection"}
+	}
+	s.connection.Unsubscribe(s.Event, string(s.Object), s.Id)
+
+	req := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "unsubscribe",
+		"params": map[string]interface{}{
+			"object":       s.Object,
+			"subscription": s.Id,
+		},
+	}
+	if s.connection.SessionId != "" {
+		req["params"].(map[string]interface{})["sessionId"] = s.connection.SessionId
+	}
+	_, err := waitResponse(ctx, s.connection.Request(req))
+	return err
 }
 
 // Implement setConnection that allows element to handle connection
@@ -133,6 +242,11 @@ func (elem *MediaObject) setConnection(c *Connection) {
 	elem.connection = c
 }
 
+// Implement IMediaObject
+func (elem *MediaObject) mediaObject() *MediaObject {
+	return elem
+}
+
 // Set parent of current element
 // BUG(recursion) a recursion happends while testing, I must find why
 func (elem *MediaObject) setParent(m IMediaObject) {
@@ -146,7 +260,72 @@ func (elem *MediaObject) addChild(m IMediaObject) {
 
 // setId set object id from a KMS response
 func (m *MediaObject) setId(id string) {
-	m.Id = id
+	m.Id = ObjectID(id)
+}
+
+// Invoke "operation" on the object and wait for the response, or for the
+// context to be done.
+func (elem *MediaObject) invoke(ctx context.Context, operation string, params map[string]interface{}) (Response, error) {
+	req := elem.getInvokeRequest()
+
+	reqparams := map[string]interface{}{
+		"operation": operation,
+		"object":    elem.Id,
+	}
+	if len(params) > 0 {
+		reqparams["operationParams"] = params
+	}
+	req["params"] = reqparams
+
+	return waitResponse(ctx, elem.request(req))
+}
+
+// Return the tags of the object, waiting for the response or for the context
+// to be done.
+func (elem *MediaObject) getTagsContext(ctx context.Context) ([]Tag, error) {
+	response, err := elem.invoke(ctx, "getTags", nil)
+	if err != nil {
+		return []Tag{}, err
+	}
+	tags := []Tag{}
+	err = response.decodeValue(&tags)
+	return tags, err
+}
+
+// Send a request on the object connection, adding the session to the request
+// params. Objects that were not created get an error response instead of a
+// nil pointer panic.
+func (elem *MediaObject) request(req map[string]interface{}) <-chan Response {
+	if err := elem.checkCreated(req["method"] == "create"); err != nil {
+		errchan := make(chan Response, 1)
+		errchan <- Response{Error: err}
+		return errchan
+	}
+
+	if params, ok := req["params"].(map[string]interface{}); ok && elem.connection.SessionId != "" {
+		params["sessionId"] = elem.connection.SessionId
+	}
+	return elem.connection.Request(req)
+}
+
+// Check that the object is bound to a connection and, unless "creating", that
+// it has been created in the media server.
+func (elem *MediaObject) checkCreated(creating bool) *Error {
+	if elem == nil || elem.connection == nil {
+		return &Error{
+			Code:    NotCreated,
+			Message: "Media object is not bound to a connection",
+			Data:    "create it with kurento.New or from a created MediaPipeline",
+		}
+	}
+	if !creating && elem.Id == "" {
+		return &Error{
+			Code:    NotCreated,
+			Message: "Media object has not been created",
+			Data:    "creation failed or was not requested",
+		}
+	}
+	return nil
 }
 
 // Build a prepared create request
@@ -184,14 +363,7 @@ func (m *MediaObject) getSubscribeRequest() map[string]interface{} {
 
 // String implements fmt.Stringer interface, return ID
 func (m *MediaObject) String() string {
-	return m.Id
-}
-
-// Return name of the object
-func getMediaElementType(i interface{}) string {
-	n := reflect.TypeOf(i).String()
-	p := strings.Split(n, ".")
-	return p[len(p)-1]
+	return string(m.Id)
 }
 
 func mergeOptions(a, b map[string]interface{}) {
@@ -208,6 +380,14 @@ func lowerFirst(s string) string {
 	return string(unicode.ToLower(r)) + s[n:]
 }
 
+func upperFirst(s string) string {
+	if s == "" {
+		return ""
+	}
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
 func setIfNotEmpty(param map[string]interface{}, name string, t interface{}) {
 
 	switch v := t.(type) {
@@ -195,8 +195,8 @@ type VideoCaps struct {
 }
 
 type ElementConnectionData struct {
-	Source            MediaElement
-	Sink              MediaElement
+	Source            IMediaElement
+	Sink              IMediaElement
 	Type              MediaType
 	SourceDescription string
 	SinkDescription   string
@@ -1,6 +1,9 @@
 package kurento
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // Base for all objects that can be created in the media server.
 type MediaObject struct {
@@ -17,7 +20,7 @@ type MediaObject struct {
 	Parent IMediaObject
 
 	// unique identifier of the mediaobject.
-	Id string
+	Id ObjectID
 
 	// Childs of current object, all returned objects have parent set to current
 	// object
@@ -57,13 +60,10 @@ func (elem *MediaObject) AddTag(key string, value string) error {
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -87,13 +87,10 @@ func (elem *MediaObject) RemoveTag(key string) error {
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -119,13 +116,10 @@ func (elem *MediaObject) GetTag(key string) (string, error) {
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// // The value associated to the given key.
 
@@ -148,23 +142,32 @@ func (elem *MediaObject) GetTags() ([]Tag, error) {
 		"operation": "getTags",
 		"object":    elem.Id,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// // An array containing all pairs key-value associated to the MediaObject.
 
 	ret := []Tag{}
-	return ret, response.Error
+	if response.Error != nil {
+		return ret, response.Error
+	}
+	err := response.decodeValue(&ret)
+	return ret, err
 
 }
 
 type IServerManager interface {
+	IMediaObject
 	GetKmd(moduleName string) (string, error)
+	GetInfo(ctx context.Context) (*ServerInfo, error)
+	GetPipelines(ctx context.Context) ([]IMediaPipeline, error)
+	GetSessions(ctx context.Context) ([]string, error)
+	GetMetadata(ctx context.Context) (string, error)
+	GetUsedMemory(ctx context.Context) (int64, error)
+	GetCpuCount(ctx context.Context) (int, error)
+	GetUsedCpu(ctx context.Context, interval int) (float64, error)
 }
 
 // This is a standalone object for managing the MediaServer
@@ -205,13 +208,10 @@ func (elem *ServerManager) GetKmd(moduleName string) (string, error) {
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// // The kmd file
 
@@ -225,6 +225,8 @@ func (elem *ServerManager) GetKmd(moduleName string) (string, error) {
 }
 
 type ISessionEndpoint interface {
+	IEndpoint
+	isSessionEndpoint()
 }
 
 // Session based endpoint. A session is considered to be started when the media
@@ -240,7 +242,25 @@ func (elem *SessionEndpoint) getConstructorParams(from IMediaObject, options map
 
 }
 
+// Implement ISessionEndpoint
+func (elem *SessionEndpoint) isSessionEndpoint() {}
+
+// OnMediaSessionStarted calls "cb" when the media session starts, e.g. when an
+// HTTP client connects. It returns the subscription handler ID.
+func (elem *SessionEndpoint) OnMediaSessionStarted(cb func()) string {
+	return elem.Subscribe("MediaSessionStarted", func(map[string]interface{}) { cb() })
+}
+
+// OnMediaSessionTerminated calls "cb" when the media session terminates, e.g.
+// after the disconnection timeout of an HTTP endpoint. It returns the
+// subscription handler ID.
+func (elem *SessionEndpoint) OnMediaSessionTerminated(cb func()) string {
+	return elem.Subscribe("MediaSessionTerminated", func(map[string]interface{}) { cb() })
+}
+
 type IHub interface {
+	IMediaObject
+	isHub()
 }
 
 // A Hub is a routing `MediaObject`. It connects several `endpoints <Endpoint>`
@@ -255,7 +275,12 @@ func (elem *Hub) getConstructorParams(from IMediaObject, options map[string]inte
 
 }
 
+// Implement IHub
+func (elem *Hub) isHub() {}
+
 type IFilter interface {
+	IMediaElement
+	isFilter()
 }
 
 // Base interface for all filters. This is a certain type of `MediaElement`, that
@@ -271,7 +296,12 @@ func (elem *Filter) getConstructorParams(from IMediaObject, options map[string]i
 
 }
 
+// Implement IFilter
+func (elem *Filter) isFilter() {}
+
 type IEndpoint interface {
+	IMediaElement
+	isEndpoint()
 }
 
 // Base interface for all end points. An Endpoint is a `MediaElement`
@@ -290,7 +320,12 @@ func (elem *Endpoint) getConstructorParams(from IMediaObject, options map[string
 
 }
 
+// Implement IEndpoint
+func (elem *Endpoint) isEndpoint() {}
+
 type IHubPort interface {
+	IMediaElement
+	isHubPort()
 }
 
 // This `MediaElement` specifies a connection with a `Hub`
@@ -313,7 +348,12 @@ func (elem *HubPort) getConstructorParams(from IMediaObject, options map[string]
 
 }
 
+// Implement IHubPort
+func (elem *HubPort) isHubPort() {}
+
 type IPassThrough interface {
+	IMediaElement
+	isPassThrough()
 }
 
 // This `MediaElement` that just passes media through
@@ -336,7 +376,11 @@ func (elem *PassThrough) getConstructorParams(from IMediaObject, options map[str
 
 }
 
+// Implement IPassThrough
+func (elem *PassThrough) isPassThrough() {}
+
 type IUriEndpoint interface {
+	IEndpoint
 	Pause() error
 	Stop() error
 }
@@ -364,13 +408,10 @@ func (elem *UriEndpoint) Pause() error {
 		"operation": "pause",
 		"object":    elem.Id,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -389,13 +430,10 @@ func (elem *UriEndpoint) Stop() error {
 		"operation": "stop",
 		"object":    elem.Id,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -407,6 +445,7 @@ func (elem *UriEndpoint) Stop() error {
 }
 
 type IMediaPipeline interface {
+	IMediaObject
 	GetGstreamerDot(details GstreamerDotDetails) (string, error)
 }
 
@@ -439,13 +478,10 @@ func (elem *MediaPipeline) GetGstreamerDot(details GstreamerDotDetails) (string,
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// // The dot graph
 
@@ -459,11 +495,16 @@ func (elem *MediaPipeline) GetGstreamerDot(details GstreamerDotDetails) (string,
 }
 
 type ISdpEndpoint interface {
+	ISessionEndpoint
 	GenerateOffer() (string, error)
 	ProcessOffer(offer string) (string, error)
 	ProcessAnswer(answer string) (string, error)
 	GetLocalSessionDescriptor() (string, error)
 	GetRemoteSessionDescriptor() (string, error)
+	Negotiation() SdpNegotiation
+	ResetNegotiation()
+	SetCodecPreferences(p CodecPreferences)
+	NegotiatedCodecs() ([]NegotiatedCodec, error)
 }
 
 // Implements an SDP negotiation endpoint able to generate and process
@@ -477,6 +518,8 @@ type SdpEndpoint struct {
 	// 0: unlimited.
 	// Default value: 500
 	MaxVideoRecvBandwidth int
+
+	negotiation negotiationState
 }
 
 // Return contructor params to be called by "Create".
@@ -490,19 +533,35 @@ func (elem *SdpEndpoint) getConstructorParams(from IMediaObject, options map[str
 // Returns:
 // // The SDP offer.
 func (elem *SdpEndpoint) GenerateOffer() (string, error) {
+	if err := elem.negotiation.beginGenerateOffer(); err != nil {
+		return "", err
+	}
+
 	req := elem.getInvokeRequest()
 
 	reqparams := map[string]interface{}{
 		"operation": "generateOffer",
 		"object":    elem.Id,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
+	if response.Error == nil {
+		// the media server has no codec configuration for its offers and keeps
+		// the offer it generated, which stays valid for answers restricted to
+		// the preferred codecs. If they cannot be applied, the state still
+		// follows the media server that has a local offer.
+		offer := response.Result["value"]
+		preferred, err := elem.negotiation.preferCodecs(offer)
+		if err != nil {
+			elem.negotiation.endGenerateOffer(offer, true)
+			return "", err
+		}
+		elem.negotiation.endGenerateOffer(preferred, true)
+		return preferred, nil
+	}
+	elem.negotiation.endGenerateOffer("", false)
 
 	// // The SDP offer.
 
@@ -520,6 +579,14 @@ func (elem *SdpEndpoint) GenerateOffer() (string, error) {
 // Returns:
 // // The chosen configuration from the ones stated in the SDP offer
 func (elem *SdpEndpoint) ProcessOffer(offer string) (string, error) {
+	offer, err := elem.negotiation.preferCodecs(offer)
+	if err != nil {
+		return "", err
+	}
+	if err := elem.negotiation.beginProcessOffer(offer); err != nil {
+		return "", err
+	}
+
 	req := elem.getInvokeRequest()
 
 	params := make(map[string]interface{})
@@ -531,13 +598,11 @@ func (elem *SdpEndpoint) ProcessOffer(offer string) (string, error) {
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
+	elem.negotiation.endProcessOffer(response.Result["value"], response.Error == nil)
 
 	// // The chosen configuration from the ones stated in the SDP offer
 
@@ -555,6 +620,10 @@ func (elem *SdpEndpoint) ProcessOffer(offer string) (string, error) {
 // Returns:
 // // Updated SDP offer, based on the answer received.
 func (elem *SdpEndpoint) ProcessAnswer(answer string) (string, error) {
+	if err := elem.negotiation.beginProcessAnswer(); err != nil {
+		return "", err
+	}
+
 	req := elem.getInvokeRequest()
 
 	params := make(map[string]interface{})
@@ -566,13 +635,11 @@ func (elem *SdpEndpoint) ProcessAnswer(answer string) (string, error) {
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
+	elem.negotiation.endProcessAnswer(answer, response.Result["value"], response.Error == nil)
 
 	// // Updated SDP offer, based on the answer received.
 
@@ -590,22 +657,25 @@ func (elem *SdpEndpoint) ProcessAnswer(answer string) (string, error) {
 // offer has been generated yet, it returns null. It an offer has been
 // generated it returns the offer and if an answer has been processed
 // it returns the negotiated local SessionSpec.
+// The descriptor tracked by this client, see Negotiation, is returned
+// without asking the media server.
 // Returns:
 // // The last agreed SessionSpec
 func (elem *SdpEndpoint) GetLocalSessionDescriptor() (string, error) {
+	if local := elem.Negotiation().LocalDescriptor; local != "" {
+		return local, nil
+	}
+
 	req := elem.getInvokeRequest()
 
 	reqparams := map[string]interface{}{
 		"operation": "getLocalSessionDescriptor",
 		"object":    elem.Id,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// // The last agreed SessionSpec
 
@@ -621,22 +691,25 @@ func (elem *SdpEndpoint) GetLocalSessionDescriptor() (string, error) {
 // This method gives access to the remote session description.
 // .. note:: This method returns the media previously agreed after a complete
 // offer-answer exchange. If no media has been agreed yet, it returns null.
+// The descriptor tracked by this client, see Negotiation, is returned
+// without asking the media server.
 // Returns:
 // // The last agreed User Agent session description
 func (elem *SdpEndpoint) GetRemoteSessionDescriptor() (string, error) {
+	if remote := elem.Negotiation().RemoteDescriptor; remote != "" {
+		return remote, nil
+	}
+
 	req := elem.getInvokeRequest()
 
 	reqparams := map[string]interface{}{
 		"operation": "getRemoteSessionDescriptor",
 		"object":    elem.Id,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// // The last agreed User Agent session description
 
@@ -650,6 +723,7 @@ func (elem *SdpEndpoint) GetRemoteSessionDescriptor() (string, error) {
 }
 
 type IBaseRtpEndpoint interface {
+	ISdpEndpoint
 	GetStats(mediaType MediaType) (map[string]Stats, error)
 }
 
@@ -708,24 +782,26 @@ func (elem *BaseRtpEndpoint) GetStats(mediaType MediaType) (map[string]Stats, er
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// // Delivers a successful result in the form of a RTC stats report. A RTC stats
 	// // report represents a map between strings, identifying the inspected objects
 	// // (RTCStats.id), and their corresponding RTCStats objects.
 
 	ret := map[string]Stats{}
-	return ret, response.Error
+	if response.Error != nil {
+		return ret, response.Error
+	}
+	err := response.decodeValue(&ret)
+	return ret, err
 
 }
 
 type IMediaElement interface {
+	IMediaObject
 	GetSourceConnections(mediaType MediaType, description string) ([]ElementConnectionData, error)
 	GetSinkConnections(mediaType MediaType, description string) ([]ElementConnectionData, error)
 	Connect(sink IMediaElement, mediaType MediaType, sourceMediaDescription string, sinkMediaDescription string) error
@@ -772,20 +848,19 @@ func (elem *MediaElement) GetSourceConnections(mediaType MediaType, description
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// // A list of the connections information that are sending media to this
 	// element.
 	// // The list will be empty if no sources are found.
 
-	ret := []ElementConnectionData{}
-	return ret, response.Error
+	if response.Error != nil {
+		return []ElementConnectionData{}, response.Error
+	}
+	return decodeConnections(elem.connection, response)
 
 }
 
@@ -807,20 +882,51 @@ func (elem *MediaElement) GetSinkConnections(mediaType MediaType, description st
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// // A list of the connections information that arereceiving media from this
 	// // element. The list will be empty if no sinks are found.
 
-	ret := []ElementConnectionData{}
-	return ret, response.Error
+	if response.Error != nil {
+		return []ElementConnectionData{}, response.Error
+	}
+	return decodeConnections(elem.connection, response)
+
+}
 
+// Decode a list of connections, building source and sink objects from the
+// registered types.
+func decodeConnections(c *Connection, response Response) ([]ElementConnectionData, error) {
+	var raw []struct {
+		Source            string
+		Sink              string
+		Type              MediaType
+		SourceDescription string
+		SinkDescription   string
+	}
+	if err := response.decodeValue(&raw); err != nil {
+		return []ElementConnectionData{}, err
+	}
+
+	ret := make([]ElementConnectionData, 0, len(raw))
+	for _, r := range raw {
+		data := ElementConnectionData{
+			Type:              r.Type,
+			SourceDescription: r.SourceDescription,
+			SinkDescription:   r.SinkDescription,
+		}
+		if source, ok := decodeObjectRef(c, r.Source).(IMediaElement); ok {
+			data.Source = source
+		}
+		if sink, ok := decodeObjectRef(c, r.Sink).(IMediaElement); ok {
+			data.Sink = sink
+		}
+		ret = append(ret, data)
+	}
+	return ret, nil
 }
 
 // Connects two elements, with the given restrictions, current `MediaElement` will
@@ -842,13 +948,10 @@ func (elem *MediaElement) Connect(sink IMediaElement, mediaType MediaType, sourc
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -877,13 +980,10 @@ func (elem *MediaElement) Disconnect(sink IMediaElement, mediaType MediaType, so
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -908,13 +1008,10 @@ func (elem *MediaElement) SetAudioFormat(caps AudioCaps) error {
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -939,13 +1036,10 @@ func (elem *MediaElement) SetVideoFormat(caps VideoCaps) error {
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -972,13 +1066,10 @@ func (elem *MediaElement) GetGstreamerDot(details GstreamerDotDetails) (string,
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// // The dot graph
 
@@ -1006,13 +1097,10 @@ func (elem *MediaElement) SetOutputBitrate(bitrate int) error {
 		"object":          elem.Id,
 		"operationParams": params,
 	}
-	if elem.connection.SessionId != "" {
-		reqparams["sessionId"] = elem.connection.SessionId
-	}
 	req["params"] = reqparams
 
 	// Call server and wait response
-	response := <-elem.connection.Request(req)
+	response := <-elem.request(req)
 
 	// Returns error or nil
 	if response.Error != nil {
@@ -0,0 +1,373 @@
+package kurento
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+// fakeKms is a media server answering JSON-RPC requests over a websocket. It
+// keeps objects, tags and connections, and runs transactions. Other invoked
+// operations are given to "invoke", if set.
+type fakeKms struct {
+	t    *testing.T
+	conn *Connection
+
+	mu          sync.Mutex
+	calls       []fakeCall
+	objects     map[string]*fakeObject
+	connections []map[string]interface{}
+	next        int
+	invoke      func(object, operation string, params map[string]interface{}) (interface{}, *Error)
+
+	wmu sync.Mutex
+	ws  *websocket.Conn
+}
+
+// A request received by the fake media server.
+type fakeCall struct {
+	Method string
+	Params map[string]interface{}
+}
+
+type fakeObject struct {
+	id     string
+	parent string
+	tags   []map[string]interface{}
+}
+
+// Start a fake media server and return it with a connection to it.
+func newFakeKms(t *testing.T) *fakeKms {
+	t.Helper()
+	Debug(false)
+	k := &fakeKms{t: t, objects: make(map[string]*fakeObject)}
+	server := httptest.NewServer(websocket.Handler(k.serve))
+	host := "ws" + strings.TrimPrefix(server.URL, "http")
+	k.conn = NewConnection(host)
+	t.Cleanup(func() {
+		k.wmu.Lock()
+		if k.ws != nil {
+			k.ws.Close()
+		}
+		k.wmu.Unlock()
+		server.Close()
+		delete(connections, host)
+	})
+	return k
+}
+
+func (k *fakeKms) serve(ws *websocket.Conn) {
+	k.wmu.Lock()
+	k.ws = ws
+	k.wmu.Unlock()
+	for {
+		var req struct {
+			Id     float64
+			Method string
+			Params map[string]interface{}
+		}
+		if err := websocket.JSON.Receive(ws, &req); err != nil {
+			return
+		}
+		value, err := k.handle(req.Method, req.Params)
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": req.Id}
+		if err != nil {
+			res["error"] = err
+		} else {
+			result := map[string]interface{}{"sessionId": "fake-session"}
+			if value != nil {
+				result["value"] = value
+			}
+			res["result"] = result
+		}
+		k.send(res)
+	}
+}
+
+func (k *fakeKms) send(message interface{}) {
+	k.wmu.Lock()
+	defer k.wmu.Unlock()
+	if err := websocket.JSON.Send(k.ws, message); err != nil {
+		k.t.Errorf("fake media server: %v", err)
+	}
+}
+
+// Send an event of an object to the client.
+func (k *fakeKms) event(typ, object string, data map[string]interface{}) {
+	k.send(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "onEvent",
+		"params": map[string]interface{}{
+			"value": map[string]interface{}{"type": typ, "object": object, "data": data},
+		},
+	})
+}
+
+// Return the requests received with the method, "" for all of them.
+func (k *fakeKms) requests(method string) []fakeCall {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	ret := []fakeCall{}
+	for _, c := range k.calls {
+		if method == "" || c.Method == method {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// Return the operations invoked, as "<operation> <object>".
+func (k *fakeKms) invoked() []string {
+	ret := []string{}
+	for _, c := range k.requests("invoke") {
+		ret = append(ret, fmt.Sprintf("%s %s", c.Params["operation"], c.Params["object"]))
+	}
+	return ret
+}
+
+// Return the released objects, in order.
+func (k *fakeKms) released() []string {
+	ret := []string{}
+	for _, c := range k.requests("release") {
+		ret = append(ret, fmt.Sprint(c.Params["object"]))
+	}
+	return ret
+}
+
+// Add an object, as if another client created it, and return its ID.
+func (k *fakeKms) add(typeName, parent string) string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.addLocked(typeName, parent)
+}
+
+func (k *fakeKms) addLocked(typeName, parent string) string {
+	if !strings.Contains(typeName, ".") {
+		typeName = "kurento." + typeName
+	}
+	k.next++
+	id := fmt.Sprintf("%08x-0000-4000-8000-000000000000_%s", k.next, typeName)
+	if parent != "" {
+		id = ObjectID(parent).PipelineID().String() + "/" + id
+	}
+	k.objects[id] = &fakeObject{id: id, parent: parent}
+	return id
+}
+
+// Return the IDs of the objects, sorted.
+func (k *fakeKms) ids() []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	ret := []string{}
+	for id := range k.objects {
+		ret = append(ret, id)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (k *fakeKms) handle(method string, params map[string]interface{}) (interface{}, *Error) {
+	k.mu.Lock()
+	k.calls = append(k.calls, fakeCall{method, params})
+	k.mu.Unlock()
+
+	if method == "transaction" {
+		return k.transaction(params)
+	}
+	return k.apply(method, params)
+}
+
+func (k *fakeKms) apply(method string, params map[string]interface{}) (interface{}, *Error) {
+	object, _ := params["object"].(string)
+	switch method {
+	case "create":
+		return k.create(params)
+	case "release":
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		if k.objects[object] == nil {
+			return nil, k.notFound(object)
+		}
+		k.releaseLocked(object)
+		return nil, nil
+	case "subscribe":
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		k.next++
+		return fmt.Sprintf("subscription-%d", k.next), nil
+	case "unsubscribe":
+		return nil, nil
+	case "invoke":
+		operation, _ := params["operation"].(string)
+		opparams, _ := params["operationParams"].(map[string]interface{})
+		k.mu.Lock()
+		o := k.objects[object]
+		if o == nil && object != "manager_ServerManager" {
+			k.mu.Unlock()
+			return nil, k.notFound(object)
+		}
+		value, handled := k.builtin(o, operation, opparams)
+		k.mu.Unlock()
+		if handled {
+			return value, nil
+		}
+		if k.invoke != nil {
+			return k.invoke(object, operation, opparams)
+		}
+		return nil, &Error{Code: -32601, Message: "unknown operation " + operation}
+	}
+	return nil, &Error{Code: -32601, Message: "unknown method " + method}
+}
+
+func (k *fakeKms) notFound(object string) *Error {
+	return &Error{Code: ObjectNotFound, Message: "Object '" + object + "' not found"}
+}
+
+func (k *fakeKms) create(params map[string]interface{}) (interface{}, *Error) {
+	typeName, _ := params["type"].(string)
+	constructor, _ := params["constructorParams"].(map[string]interface{})
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if canonicalTypeName(typeName) == "MediaPipeline" {
+		return k.addLocked(typeName, ""), nil
+	}
+	parent, _ := constructor["mediaPipeline"].(string)
+	if parent == "" {
+		parent, _ = constructor["hub"].(string)
+	}
+	if parent == "" {
+		return nil, &Error{Code: -32602, Message: "'mediaPipeline' parameter is required"}
+	}
+	if k.objects[parent] == nil {
+		return nil, k.notFound(parent)
+	}
+	return k.addLocked(typeName, parent), nil
+}
+
+// Release an object and its children. Must be called with mu held.
+func (k *fakeKms) releaseLocked(id string) {
+	delete(k.objects, id)
+	for child, o := range k.objects {
+		if o.parent == id {
+			k.releaseLocked(child)
+		}
+	}
+	connections := k.connections[:0]
+	for _, c := range k.connections {
+		if c["source"] != id && c["sink"] != id {
+			connections = append(connections, c)
+		}
+	}
+	k.connections = connections
+}
+
+// Run the operations the fake keeps state for. Must be called with mu held.
+func (k *fakeKms) builtin(o *fakeObject, operation string, params map[string]interface{}) (interface{}, bool) {
+	if o == nil {
+		return nil, false
+	}
+	switch operation {
+	case "getChildren":
+		children := []string{}
+		for id, child := range k.objects {
+			if child.parent == o.id {
+				children = append(children, id)
+			}
+		}
+		sort.Strings(children)
+		return children, true
+	case "getParent":
+		if o.parent == "" {
+			return nil, true
+		}
+		return o.parent, true
+	case "getMediaPipeline":
+		return ObjectID(o.id).PipelineID().String(), true
+	case "getTags":
+		tags := []map[string]interface{}{}
+		return append(tags, o.tags...), true
+	case "addTag":
+		o.tags = append(o.tags, map[string]interface{}{"key": params["key"], "value": params["value"]})
+		return nil, true
+	case "connect":
+		types := []interface{}{params["mediaType"]}
+		if params["mediaType"] == nil {
+			types = []interface{}{MEDIATYPE_AUDIO, MEDIATYPE_VIDEO}
+		}
+		for _, t := range types {
+			k.connections = append(k.connections, map[string]interface{}{
+				"source":            o.id,
+				"sink":              params["sink"],
+				"type":              t,
+				"sourceDescription": params["sourceMediaDescription"],
+				"sinkDescription":   params["sinkMediaDescription"],
+			})
+		}
+		return nil, true
+	case "getSinkConnections":
+		ret := []map[string]interface{}{}
+		for _, c := range k.connections {
+			if c["source"] == o.id {
+				ret = append(ret, c)
+			}
+		}
+		return ret, true
+	}
+	return nil, false
+}
+
+// Run the operations of a transaction, replacing "newref:<n>" by the ID
+// created by operation n.
+func (k *fakeKms) transaction(params map[string]interface{}) (interface{}, *Error) {
+	operations, _ := params["operations"].([]interface{})
+	created := make(map[string]string)
+	results := []interface{}{}
+	for i, op := range operations {
+		op, _ := op.(map[string]interface{})
+		method, _ := op["method"].(string)
+		opparams, _ := resolveRefs(op["params"], created).(map[string]interface{})
+		value, err := k.apply(method, opparams)
+		if err != nil {
+			results = append(results, map[string]interface{}{"id": i, "error": err})
+			continue
+		}
+		if id, ok := value.(string); ok && method == "create" {
+			created[newRefPrefix+fmt.Sprint(i)] = id
+		}
+		results = append(results, map[string]interface{}{"id": i, "result": map[string]interface{}{"value": value}})
+	}
+	return results, nil
+}
+
+func resolveRefs(v interface{}, created map[string]string) interface{} {
+	switch v := v.(type) {
+	case string:
+		if id, ok := created[v]; ok {
+			return id
+		}
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			ret[key] = resolveRefs(value, created)
+		}
+		return ret
+	}
+	return v
+}
+
+// Decode JSON into a generic value, for comparisons with decoded requests.
+func jsonValue(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
@@ -0,0 +1,212 @@
+package kurento
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Factory returns a new, empty media object of a registered type.
+type Factory func() IMediaObject
+
+var registry = struct {
+	sync.RWMutex
+	factories map[string]Factory      // KMS type name -> factory
+	names     map[reflect.Type]string // Go type -> KMS type name
+	infos     map[string]typeInfo     // KMS type name -> what is known of the type
+}{
+	factories: make(map[string]Factory),
+	names:     make(map[reflect.Type]string),
+	infos:     make(map[string]typeInfo),
+}
+
+// What is known of a type of the core module, used to validate specs, take
+// snapshots and lint topologies. Types registered by users have none.
+type typeInfo struct {
+	options    map[string]optionKind // constructor options, besides the parent
+	properties []string              // properties saved in snapshots
+	sink       bool                  // consumes media, useless without a source
+	audioVideo bool                  // usually carries both audio and video
+}
+
+// Kind of a constructor option value.
+type optionKind int
+
+const (
+	optionString optionKind = iota
+	optionBool
+	optionNumber
+	optionObject
+)
+
+// Registered types implement the interfaces of their KMS class
+var (
+	_ IMediaPipeline       = (*MediaPipeline)(nil)
+	_ IServerManager       = (*ServerManager)(nil)
+	_ IWebRtcEndpoint      = (*WebRtcEndpoint)(nil)
+	_ IRtpEndpoint         = (*RtpEndpoint)(nil)
+	_ IPlayerEndpoint      = (*PlayerEndpoint)(nil)
+	_ IRecorderEndpoint    = (*RecorderEndpoint)(nil)
+	_ IHttpPostEndpoint    = (*HttpPostEndpoint)(nil)
+	_ IHttpGetEndpoint     = (*HttpGetEndpoint)(nil)
+	_ IPassThrough         = (*PassThrough)(nil)
+	_ IHubPort             = (*HubPort)(nil)
+	_ IComposite           = (*Composite)(nil)
+	_ IDispatcher          = (*Dispatcher)(nil)
+	_ IDispatcherOneToMany = (*DispatcherOneToMany)(nil)
+	_ IAlphaBlending       = (*AlphaBlending)(nil)
+	_ IMixer               = (*Mixer)(nil)
+	_ IFilter              = (*Filter)(nil)
+)
+
+// Properties saved in snapshots of RTP endpoints.
+var rtpSnapshotProperties = []string{
+	"maxVideoRecvBandwidth", "minVideoRecvBandwidth",
+	"maxVideoSendBandwidth", "minVideoSendBandwidth",
+}
+
+func init() {
+	registerType("MediaPipeline", func() IMediaObject { return new(MediaPipeline) }, typeInfo{})
+	registerType("ServerManager", func() IMediaObject { return new(ServerManager) }, typeInfo{})
+	registerType("WebRtcEndpoint", func() IMediaObject { return new(WebRtcEndpoint) }, typeInfo{
+		options: map[string]optionKind{
+			"recvonly":           optionBool,
+			"sendonly":           optionBool,
+			"useDataChannels":    optionBool,
+			"certificateKeyType": optionString,
+			"qosDscp":            optionString,
+		},
+		properties: append([]string{"stunServerAddress", "stunServerPort", "turnUrl"}, rtpSnapshotProperties...),
+		audioVideo: true,
+	})
+	registerType("RtpEndpoint", func() IMediaObject { return new(RtpEndpoint) }, typeInfo{
+		options: map[string]optionKind{
+			"crypto":  optionObject,
+			"useIpv6": optionBool,
+		},
+		properties: rtpSnapshotProperties,
+		audioVideo: true,
+	})
+	registerType("PlayerEndpoint", func() IMediaObject { return new(PlayerEndpoint) }, typeInfo{
+		options: map[string]optionKind{
+			"uri":             optionString,
+			"useEncodedMedia": optionBool,
+			"networkCache":    optionNumber,
+		},
+		properties: []string{"uri"},
+		audioVideo: true,
+	})
+	registerType("RecorderEndpoint", func() IMediaObject { return new(RecorderEndpoint) }, typeInfo{
+		options: map[string]optionKind{
+			"uri":               optionString,
+			"mediaProfile":      optionString,
+			"stopOnEndOfStream": optionBool,
+		},
+		properties: []string{"uri"},
+		sink:       true,
+		audioVideo: true,
+	})
+	registerType("HttpPostEndpoint", func() IMediaObject { return new(HttpPostEndpoint) }, typeInfo{
+		options: map[string]optionKind{
+			"disconnectionTimeout": optionNumber,
+			"useEncodedMedia":      optionBool,
+		},
+		audioVideo: true,
+	})
+	registerType("HttpGetEndpoint", func() IMediaObject { return new(HttpGetEndpoint) }, typeInfo{
+		options: map[string]optionKind{
+			"terminateOnEOS":       optionBool,
+			"mediaProfile":         optionString,
+			"disconnectionTimeout": optionNumber,
+		},
+		sink:       true,
+		audioVideo: true,
+	})
+	registerType("PassThrough", func() IMediaObject { return new(PassThrough) }, typeInfo{audioVideo: true})
+	registerType("HubPort", func() IMediaObject { return new(HubPort) }, typeInfo{audioVideo: true})
+	registerType("Composite", func() IMediaObject { return new(Composite) }, typeInfo{})
+	registerType("Dispatcher", func() IMediaObject { return new(Dispatcher) }, typeInfo{})
+	registerType("DispatcherOneToMany", func() IMediaObject { return new(DispatcherOneToMany) }, typeInfo{})
+	registerType("AlphaBlending", func() IMediaObject { return new(AlphaBlending) }, typeInfo{})
+	registerType("Mixer", func() IMediaObject { return new(Mixer) }, typeInfo{})
+}
+
+// Register associates a KMS type name with a Go factory. It is used both to
+// name the type in "create" requests and to build Go objects from references
+// returned by the server. Types of the core "kurento" module may be given with
+// or without the "kurento." prefix, other modules must be qualified, e.g.
+// "platedetector.PlateDetectorFilter".
+//
+// Registering a name twice replaces the previous factory.
+func Register(typeName string, factory Factory) {
+	typeName = canonicalTypeName(typeName)
+	t := reflect.TypeOf(factory())
+
+	registry.Lock()
+	defer registry.Unlock()
+	registry.factories[typeName] = factory
+	registry.names[t] = typeName
+}
+
+// Register a type of the core module with what is known of it.
+func registerType(typeName string, factory Factory, info typeInfo) {
+	Register(typeName, factory)
+	registry.Lock()
+	defer registry.Unlock()
+	registry.infos[canonicalTypeName(typeName)] = info
+}
+
+// Return what is known of a type, false for types registered by users.
+func registeredInfo(typeName string) (typeInfo, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	info, ok := registry.infos[canonicalTypeName(typeName)]
+	return info, ok
+}
+
+// RegisteredType returns the KMS type name registered for the Go type of "m".
+func RegisteredType(m IMediaObject) (string, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	name, ok := registry.names[reflect.TypeOf(m)]
+	return name, ok
+}
+
+// NewObject returns a new, not yet created, object of the given KMS type.
+func NewObject(typeName string) (IMediaObject, error) {
+	registry.RLock()
+	factory, ok := registry.factories[canonicalTypeName(typeName)]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("kurento: unknown type %q, use Register to add it", typeName)
+	}
+	return factory(), nil
+}
+
+// Return the KMS type name to send in a "create" request for "m".
+func getMediaElementType(m IMediaObject) (string, error) {
+	name, ok := RegisteredType(m)
+	if !ok {
+		return "", fmt.Errorf("kurento: type %T is not registered, use Register to add it", m)
+	}
+	return name, nil
+}
+
+// Build a Go object from a reference returned by the server. Unknown types
+// are decoded as a generic MediaElement so that they can still be released or
+// connected.
+func decodeObjectRef(c *Connection, id string) IMediaObject {
+	m, err := NewObject(ObjectID(id).Type())
+	if err != nil {
+		m = new(MediaElement)
+	}
+	m.setConnection(c)
+	m.setId(id)
+	return m
+}
+
+// Core types are registered without their module name.
+func canonicalTypeName(typeName string) string {
+	return strings.TrimPrefix(typeName, "kurento.")
+}
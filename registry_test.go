@@ -0,0 +1,109 @@
+package kurento
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// A filter of another module, as users register them.
+type plateDetectorFilter struct {
+	Filter
+}
+
+func init() {
+	Register("platedetector.PlateDetectorFilter", func() IMediaObject { return new(plateDetectorFilter) })
+}
+
+func TestRegistry(t *testing.T) {
+	tests := []struct {
+		typeName string
+		want     reflect.Type
+		name     string
+	}{
+		{"WebRtcEndpoint", reflect.TypeOf(new(WebRtcEndpoint)), "WebRtcEndpoint"},
+		{"kurento.WebRtcEndpoint", reflect.TypeOf(new(WebRtcEndpoint)), "WebRtcEndpoint"},
+		{"kurento.HubPort", reflect.TypeOf(new(HubPort)), "HubPort"},
+		{"platedetector.PlateDetectorFilter", reflect.TypeOf(new(plateDetectorFilter)), "platedetector.PlateDetectorFilter"},
+	}
+	for _, tt := range tests {
+		m, err := NewObject(tt.typeName)
+		if err != nil {
+			t.Errorf("NewObject(%q): %v", tt.typeName, err)
+			continue
+		}
+		if reflect.TypeOf(m) != tt.want {
+			t.Errorf("NewObject(%q) is a %T, want %s", tt.typeName, m, tt.want)
+		}
+		if name, ok := RegisteredType(m); !ok || name != tt.name {
+			t.Errorf("RegisteredType(%T) = %q, %v, want %q", m, name, ok, tt.name)
+		}
+	}
+
+	for _, typeName := range []string{"PlateDetectorFilter", "other.WebRtcEndpoint", ""} {
+		if m, err := NewObject(typeName); err == nil {
+			t.Errorf("NewObject(%q) = %T, want an error", typeName, m)
+		}
+	}
+	if _, err := getMediaElementType(new(Filter)); err == nil {
+		t.Error("unregistered Filter has a type name")
+	}
+}
+
+func TestDecodeObjectRef(t *testing.T) {
+	const pipeline = "6ba9067f-e5bf-4ba2-8b0b-bbc4e4e3c0a5_kurento.MediaPipeline"
+	tests := []struct {
+		id   string
+		want reflect.Type
+	}{
+		{pipeline, reflect.TypeOf(new(MediaPipeline))},
+		{pipeline + "/d8fc3ce9_kurento.WebRtcEndpoint", reflect.TypeOf(new(WebRtcEndpoint))},
+		{pipeline + "/f0e1_platedetector.PlateDetectorFilter", reflect.TypeOf(new(plateDetectorFilter))},
+		{pipeline + "/a1b2_chroma.ChromaFilter", reflect.TypeOf(new(MediaElement))},
+	}
+	c := new(Connection)
+	for _, tt := range tests {
+		m := decodeObjectRef(c, tt.id)
+		if reflect.TypeOf(m) != tt.want {
+			t.Errorf("decodeObjectRef(%q) is a %T, want %s", tt.id, m, tt.want)
+		}
+		if elem := m.mediaObject(); elem.Id != ObjectID(tt.id) || elem.connection != c {
+			t.Errorf("decodeObjectRef(%q) has id %q, connection %p", tt.id, elem.Id, elem.connection)
+		}
+	}
+}
+
+func TestCreateOtherModule(t *testing.T) {
+	k := newFakeKms(t)
+	ctx := context.Background()
+	pipeline, err := k.conn.NewPipeline(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := map[string]interface{}{"useEncodedMedia": true}
+	filter, err := New[*plateDetectorFilter](ctx, pipeline, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.Id.Type() != "platedetector.PlateDetectorFilter" || filter.Id.PipelineID() != pipeline.Id {
+		t.Errorf("created %s", filter.Id)
+	}
+	if len(options) != 1 {
+		t.Errorf("options of the caller changed: %v", options)
+	}
+
+	creates := k.requests("create")
+	params := creates[len(creates)-1].Params
+	want := map[string]interface{}{
+		"type": "platedetector.PlateDetectorFilter",
+		"constructorParams": map[string]interface{}{
+			"mediaPipeline":   string(pipeline.Id),
+			"useEncodedMedia": true,
+		},
+		"sessionId": "fake-session",
+	}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("create params %v, want %v", params, want)
+	}
+}
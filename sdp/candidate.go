@@ -0,0 +1,121 @@
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Type of an ICE candidate, see RFC 8445.
+type CandidateType string
+
+// Implement fmt.Stringer interface
+func (t CandidateType) String() string {
+	return string(t)
+}
+
+const (
+	CandidateHost  CandidateType = "host"
+	CandidateSrflx CandidateType = "srflx"
+	CandidatePrflx CandidateType = "prflx"
+	CandidateRelay CandidateType = "relay"
+)
+
+// CandidateExtension is a key/value pair at the end of a candidate attribute,
+// e.g. "generation 0".
+type CandidateExtension struct {
+	Key   string
+	Value string
+}
+
+// Candidate is a parsed "candidate" attribute, see RFC 8839.
+type Candidate struct {
+	Foundation string
+	Component  int
+	Transport  string // "udp" or "tcp", lowercase
+	Priority   uint32
+	Address    string
+	Port       int
+	Type       CandidateType
+
+	// Base of srflx, prflx and relay candidates, if given
+	RelatedAddress string
+	RelatedPort    int
+
+	// "active", "passive" or "so", for TCP candidates
+	TCPType string
+
+	// Other key/value pairs, in their original order
+	Extensions []CandidateExtension
+}
+
+// ParseCandidate parses a candidate attribute, with or without the "a=" and
+// "candidate:" prefixes.
+func ParseCandidate(s string) (*Candidate, error) {
+	line := strings.TrimSpace(s)
+	line = strings.TrimPrefix(line, "a=")
+	line = strings.TrimPrefix(line, "candidate:")
+
+	fields := strings.Fields(line)
+	if len(fields) < 8 || fields[6] != "typ" {
+		return nil, fmt.Errorf("sdp: invalid candidate %q", s)
+	}
+
+	c := &Candidate{
+		Foundation: fields[0],
+		Transport:  strings.ToLower(fields[2]),
+		Address:    fields[4],
+		Type:       CandidateType(fields[7]),
+	}
+	var err error
+	if c.Component, err = strconv.Atoi(fields[1]); err != nil {
+		return nil, fmt.Errorf("sdp: invalid candidate component %q", fields[1])
+	}
+	priority, err := strconv.ParseUint(fields[3], 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("sdp: invalid candidate priority %q", fields[3])
+	}
+	c.Priority = uint32(priority)
+	if c.Port, err = strconv.Atoi(fields[5]); err != nil {
+		return nil, fmt.Errorf("sdp: invalid candidate port %q", fields[5])
+	}
+
+	rest := fields[8:]
+	if len(rest)%2 != 0 {
+		return nil, fmt.Errorf("sdp: invalid candidate extensions in %q", s)
+	}
+	for i := 0; i < len(rest); i += 2 {
+		key, value := rest[i], rest[i+1]
+		switch key {
+		case "raddr":
+			c.RelatedAddress = value
+		case "rport":
+			if c.RelatedPort, err = strconv.Atoi(value); err != nil {
+				return nil, fmt.Errorf("sdp: invalid candidate rport %q", value)
+			}
+		case "tcptype":
+			c.TCPType = value
+		default:
+			c.Extensions = append(c.Extensions, CandidateExtension{key, value})
+		}
+	}
+	return c, nil
+}
+
+// Implement fmt.Stringer interface. Return the attribute value with the
+// "candidate:" prefix.
+func (c Candidate) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "candidate:%s %d %s %d %s %d typ %s",
+		c.Foundation, c.Component, c.Transport, c.Priority, c.Address, c.Port, c.Type)
+	if c.RelatedAddress != "" {
+		fmt.Fprintf(&b, " raddr %s rport %d", c.RelatedAddress, c.RelatedPort)
+	}
+	if c.TCPType != "" {
+		fmt.Fprintf(&b, " tcptype %s", c.TCPType)
+	}
+	for _, e := range c.Extensions {
+		fmt.Fprintf(&b, " %s %s", e.Key, e.Value)
+	}
+	return b.String()
+}
@@ -0,0 +1,91 @@
+package sdp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCandidate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Candidate
+	}{
+		{
+			"candidate:1 1 udp 2122260223 192.168.1.10 54321 typ host generation 0",
+			Candidate{
+				Foundation: "1", Component: 1, Transport: "udp", Priority: 2122260223,
+				Address: "192.168.1.10", Port: 54321, Type: CandidateHost,
+				Extensions: []CandidateExtension{{"generation", "0"}},
+			},
+		},
+		{
+			"candidate:842163049 1 udp 1677729535 203.0.113.7 61234 typ srflx raddr 192.168.1.10 rport 54321",
+			Candidate{
+				Foundation: "842163049", Component: 1, Transport: "udp", Priority: 1677729535,
+				Address: "203.0.113.7", Port: 61234, Type: CandidateSrflx,
+				RelatedAddress: "192.168.1.10", RelatedPort: 54321,
+			},
+		},
+		{
+			"candidate:3 2 tcp 1518280447 ::1 9 typ host tcptype active generation 0 network-id 1",
+			Candidate{
+				Foundation: "3", Component: 2, Transport: "tcp", Priority: 1518280447,
+				Address: "::1", Port: 9, Type: CandidateHost, TCPType: "active",
+				Extensions: []CandidateExtension{{"generation", "0"}, {"network-id", "1"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		for _, in := range []string{tt.in, "a=" + tt.in, tt.in[len("candidate:"):]} {
+			c, err := ParseCandidate(in)
+			if err != nil {
+				t.Errorf("ParseCandidate(%q): %v", in, err)
+				continue
+			}
+			if !reflect.DeepEqual(*c, tt.want) {
+				t.Errorf("ParseCandidate(%q) = %+v, want %+v", in, *c, tt.want)
+			}
+		}
+		if s := tt.want.String(); s != tt.in {
+			t.Errorf("String() = %q, want %q", s, tt.in)
+		}
+	}
+
+	c, err := ParseCandidate("candidate:1 1 UDP 1 10.0.0.1 9 typ relay")
+	if err != nil || c.Transport != "udp" || c.Type != CandidateRelay {
+		t.Errorf("uppercase transport %+v %v", c, err)
+	}
+}
+
+func TestParseCandidateErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"candidate:1 1 udp 2122260223 192.168.1.10 54321",
+		"candidate:1 1 udp 2122260223 192.168.1.10 54321 type host",
+		"candidate:1 one udp 2122260223 192.168.1.10 54321 typ host",
+		"candidate:1 1 udp 99999999999 192.168.1.10 54321 typ host",
+		"candidate:1 1 udp 2122260223 192.168.1.10 port typ host",
+		"candidate:1 1 udp 2122260223 192.168.1.10 54321 typ host generation",
+		"candidate:1 1 udp 1 10.0.0.1 9 typ srflx raddr 10.0.0.2 rport x",
+	}
+	for _, in := range tests {
+		if _, err := ParseCandidate(in); err == nil {
+			t.Errorf("ParseCandidate(%q) did not fail", in)
+		}
+	}
+}
+
+func TestFilterCandidates(t *testing.T) {
+	m := &Media{Lines: []Line{{'a', "mid:0"}, {'a', "candidate:bad"}}}
+	m.AddCandidate(Candidate{Foundation: "1", Component: 1, Transport: "udp", Priority: 1, Address: "10.0.0.1", Port: 9, Type: CandidateHost})
+	m.AddCandidate(Candidate{Foundation: "2", Component: 1, Transport: "udp", Priority: 1, Address: "10.0.0.2", Port: 9, Type: CandidateRelay})
+	if n := len(m.Candidates()); n != 2 {
+		t.Errorf("%d candidates, want 2", n)
+	}
+
+	m.FilterCandidates(func(c *Candidate) bool { return c.Type == CandidateRelay })
+	want := []string{"a=mid:0", "a=candidate:2 1 udp 1 10.0.0.2 9 typ relay"}
+	if got := mediaLines(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("lines %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,248 @@
+package sdp
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrNoMatchingCodec is returned when restricting a media section to codecs it
+// does not have.
+var ErrNoMatchingCodec = errors.New("sdp: no matching codec in the media section")
+
+// Codec is an RTP payload type of a media section, described by its
+// "rtpmap", "fmtp" and "rtcp-fb" attributes.
+type Codec struct {
+	PayloadType int
+	Name        string // e.g. "VP8", "opus"
+	ClockRate   int
+	Channels    int // 0 when not given
+
+	// Value of the "fmtp" attribute, without the payload type
+	Fmtp string
+
+	// Values of the "rtcp-fb" attributes, without the payload type
+	Feedback []string
+}
+
+// Implement fmt.Stringer interface. Return the "rtpmap" encoding, e.g.
+// "opus/48000/2".
+func (c Codec) String() string {
+	s := c.Name + "/" + strconv.Itoa(c.ClockRate)
+	if c.Channels > 0 {
+		s += "/" + strconv.Itoa(c.Channels)
+	}
+	return s
+}
+
+// Parameter returns the value of a "fmtp" parameter, e.g. "apt" or
+// "profile-level-id".
+func (c Codec) Parameter(key string) (string, bool) {
+	for _, p := range strings.Split(c.Fmtp, ";") {
+		k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+		if k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Static payload types that may be used without "rtpmap", see RFC 3551.
+var staticCodecs = map[int]Codec{
+	0:  {Name: "PCMU", ClockRate: 8000, Channels: 1},
+	8:  {Name: "PCMA", ClockRate: 8000, Channels: 1},
+	9:  {Name: "G722", ClockRate: 8000, Channels: 1},
+	18: {Name: "G729", ClockRate: 8000, Channels: 1},
+}
+
+// Codecs returns the codecs of the media section, in preference order.
+// Formats that are not payload types, e.g. "webrtc-datachannel", are skipped.
+func (m *Media) Codecs() []Codec {
+	codecs := []Codec{}
+	for _, f := range m.Formats {
+		pt, err := strconv.Atoi(f)
+		if err != nil {
+			continue
+		}
+		codec := staticCodecs[pt]
+		codec.PayloadType = pt
+		codecs = append(codecs, codec)
+	}
+
+	index := make(map[int]int)
+	for i, c := range codecs {
+		index[c.PayloadType] = i
+	}
+	for _, a := range m.Attributes() {
+		pt, value, ok := payloadAttribute(a)
+		if !ok {
+			continue
+		}
+		i, found := index[pt]
+		if !found {
+			continue
+		}
+		switch a.Key {
+		case "rtpmap":
+			parts := strings.Split(value, "/")
+			codecs[i].Name = parts[0]
+			if len(parts) > 1 {
+				codecs[i].ClockRate, _ = strconv.Atoi(parts[1])
+			}
+			if len(parts) > 2 {
+				codecs[i].Channels, _ = strconv.Atoi(parts[2])
+			}
+		case "fmtp":
+			codecs[i].Fmtp = value
+		case "rtcp-fb":
+			codecs[i].Feedback = append(codecs[i].Feedback, value)
+		}
+	}
+	return codecs
+}
+
+// Codec returns the first codec named "name", case insensitive.
+func (m *Media) Codec(name string) (Codec, bool) {
+	for _, c := range m.Codecs() {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return Codec{}, false
+}
+
+// Split a "rtpmap", "fmtp" or "rtcp-fb" attribute into its payload type and
+// value.
+func payloadAttribute(a Attribute) (int, string, bool) {
+	switch a.Key {
+	case "rtpmap", "fmtp", "rtcp-fb":
+	default:
+		return 0, "", false
+	}
+	pt, value, _ := strings.Cut(a.Value, " ")
+	n, err := strconv.Atoi(pt)
+	return n, value, err == nil
+}
+
+// Return the payload types of the codecs named in "names".
+func (m *Media) payloadTypes(names []string) []int {
+	codecs := m.Codecs()
+	var pts []int
+	for _, name := range names {
+		for _, c := range codecs {
+			if strings.EqualFold(c.Name, name) {
+				pts = append(pts, c.PayloadType)
+			}
+		}
+	}
+	return pts
+}
+
+// Return the payload types followed by their retransmission payload types
+// ("rtx" with "apt").
+func (m *Media) withRtx(pts []int) []int {
+	ret := append([]int(nil), pts...)
+	for _, c := range m.Codecs() {
+		if !strings.EqualFold(c.Name, "rtx") {
+			continue
+		}
+		apt, _ := c.Parameter("apt")
+		for _, pt := range pts {
+			if apt == strconv.Itoa(pt) {
+				ret = append(ret, c.PayloadType)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// RemoveCodecs removes the codecs named in "names", case insensitive, with
+// their retransmission payload types and attributes.
+func (m *Media) RemoveCodecs(names ...string) {
+	m.removePayloadTypes(m.withRtx(m.payloadTypes(names)), false)
+}
+
+// KeepCodecs removes every codec but the ones named in "names", case
+// insensitive, and their retransmission payload types. If none of them is in
+// the section, it is left unchanged and ErrNoMatchingCodec is returned.
+func (m *Media) KeepCodecs(names ...string) error {
+	return m.KeepPayloadTypes(m.payloadTypes(names)...)
+}
+
+// PreferCodecs moves the codecs named in "names", case insensitive, first and
+// in the given order. Other codecs keep their relative order.
+func (m *Media) PreferCodecs(names ...string) {
+	m.PreferPayloadTypes(m.payloadTypes(names)...)
+}
+
+// KeepPayloadTypes removes every payload type but "pts" and their
+// retransmission payload types. If none of them is in the section, it is left
+// unchanged and ErrNoMatchingCodec is returned, as a section without formats
+// is invalid.
+func (m *Media) KeepPayloadTypes(pts ...int) error {
+	found := false
+	for _, pt := range pts {
+		for _, f := range m.Formats {
+			if f == strconv.Itoa(pt) {
+				found = true
+			}
+		}
+	}
+	if !found {
+		return ErrNoMatchingCodec
+	}
+	m.removePayloadTypes(m.withRtx(pts), true)
+	return nil
+}
+
+// PreferPayloadTypes moves "pts", then their retransmission payload types,
+// first. Other formats keep their relative order.
+func (m *Media) PreferPayloadTypes(pts ...int) {
+	present := make(map[string]bool)
+	for _, f := range m.Formats {
+		present[f] = true
+	}
+	preferred := []string{}
+	seen := make(map[string]bool)
+	for _, pt := range m.withRtx(pts) {
+		f := strconv.Itoa(pt)
+		if present[f] && !seen[f] {
+			preferred = append(preferred, f)
+			seen[f] = true
+		}
+	}
+	formats := preferred
+	for _, f := range m.Formats {
+		if !seen[f] {
+			formats = append(formats, f)
+		}
+	}
+	m.Formats = formats
+}
+
+// Remove the payload types, or keep only them if "keep", from the formats
+// and their attributes.
+func (m *Media) removePayloadTypes(pts []int, keep bool) {
+	listed := make(map[int]bool)
+	for _, pt := range pts {
+		listed[pt] = true
+	}
+	removed := func(pt int) bool { return listed[pt] != keep }
+
+	formats := []string{}
+	for _, f := range m.Formats {
+		if pt, err := strconv.Atoi(f); err != nil || !removed(pt) {
+			formats = append(formats, f)
+		}
+	}
+	m.Formats = formats
+
+	m.Lines = removeLines(m.Lines, func(l Line) bool {
+		if l.Type != 'a' {
+			return false
+		}
+		pt, _, ok := payloadAttribute(parseAttribute(l))
+		return ok && removed(pt)
+	})
+}
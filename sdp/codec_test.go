@@ -0,0 +1,106 @@
+package sdp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodecs(t *testing.T) {
+	s := parseTestOffer(t)
+	codecs := s.Media[1].Codecs()
+	if len(codecs) != 4 {
+		t.Fatalf("codecs %+v", codecs)
+	}
+	vp8 := codecs[0]
+	if vp8.String() != "VP8/90000" || !reflect.DeepEqual(vp8.Feedback, []string{"nack"}) {
+		t.Errorf("VP8 %+v", vp8)
+	}
+	if apt, _ := codecs[3].Parameter("apt"); apt != "102" {
+		t.Errorf("H264 rtx apt %q", apt)
+	}
+	if mode, _ := codecs[2].Parameter("packetization-mode"); mode != "1" {
+		t.Errorf("H264 packetization-mode %q", mode)
+	}
+
+	audio := s.Media[0].Codecs()
+	if len(audio) != 2 || audio[0].String() != "opus/48000/2" || audio[1].String() != "PCMU/8000/1" {
+		t.Errorf("audio codecs %+v", audio)
+	}
+}
+
+func TestEditCodecs(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(m *Media) error
+		formats []string
+		lines   []string
+		err     error
+	}{
+		{
+			"remove VP8 and its rtx",
+			func(m *Media) error { m.RemoveCodecs("vp8"); return nil },
+			[]string{"102", "103"},
+			[]string{
+				"i=camera", "c=IN IP4 0.0.0.0", "a=mid:1", "a=sendrecv",
+				"a=rtpmap:102 H264/90000",
+				"a=fmtp:102 level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42001f",
+				"a=rtpmap:103 rtx/90000", "a=fmtp:103 apt=102",
+			},
+			nil,
+		},
+		{
+			"keep H264 and its rtx",
+			func(m *Media) error { return m.KeepCodecs("H264") },
+			[]string{"102", "103"},
+			nil,
+			nil,
+		},
+		{
+			"keep VP8 and its rtx",
+			func(m *Media) error { return m.KeepCodecs("VP8", "AV1") },
+			[]string{"96", "97"},
+			[]string{
+				"i=camera", "c=IN IP4 0.0.0.0", "a=mid:1", "a=sendrecv",
+				"a=rtpmap:96 VP8/90000", "a=rtcp-fb:96 nack",
+				"a=rtpmap:97 rtx/90000", "a=fmtp:97 apt=96",
+			},
+			nil,
+		},
+		{
+			"keep nothing",
+			func(m *Media) error { return m.KeepCodecs("AV1") },
+			[]string{"96", "97", "102", "103"},
+			nil,
+			ErrNoMatchingCodec,
+		},
+		{
+			"keep absent payload type",
+			func(m *Media) error { return m.KeepPayloadTypes(98) },
+			[]string{"96", "97", "102", "103"},
+			nil,
+			ErrNoMatchingCodec,
+		},
+		{
+			"prefer H264",
+			func(m *Media) error { m.PreferCodecs("H264"); return nil },
+			[]string{"102", "103", "96", "97"},
+			nil,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		m := parseTestOffer(t).Media[1]
+		if err := tt.edit(m); err != tt.err {
+			t.Errorf("%s: error %v, want %v", tt.name, err, tt.err)
+		}
+		if !reflect.DeepEqual(m.Formats, tt.formats) {
+			t.Errorf("%s: formats %q, want %q", tt.name, m.Formats, tt.formats)
+		}
+		if tt.lines != nil && !reflect.DeepEqual(mediaLines(m), tt.lines) {
+			t.Errorf("%s: lines %q, want %q", tt.name, mediaLines(m), tt.lines)
+		}
+		if tt.err != nil && !reflect.DeepEqual(m.Lines, parseTestOffer(t).Media[1].Lines) {
+			t.Errorf("%s: lines changed %q", tt.name, mediaLines(m))
+		}
+	}
+}
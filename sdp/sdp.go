@@ -0,0 +1,427 @@
+// Package sdp parses and edits the session descriptions exchanged with
+// SdpEndpoint, see RFC 8866.
+//
+// Descriptions are kept as ordered lines so that serializing an unmodified
+// session returns the same lines, with CRLF line endings.
+package sdp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Line is a "<type>=<value>" line.
+type Line struct {
+	Type  byte
+	Value string
+}
+
+// Implement fmt.Stringer interface
+func (l Line) String() string {
+	return string(l.Type) + "=" + l.Value
+}
+
+// Attribute is the key and value of an "a=" line. Value is empty for flags,
+// e.g. "a=sendonly".
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Session is a parsed session description.
+type Session struct {
+	// Session level lines, from "v=" to the first "m="
+	Lines []Line
+
+	Media []*Media
+}
+
+// Media is a media section.
+type Media struct {
+	Type     string // "audio", "video" or "application"
+	Port     int
+	NumPorts int // 0 when not given
+	Proto    string
+	Formats  []string
+
+	// Lines following the "m=" line
+	Lines []Line
+}
+
+// Parse parses a session description.
+func Parse(s string) (*Session, error) {
+	session := &Session{}
+	var media *Media
+	for n, raw := range strings.Split(s, "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		if raw == "" {
+			continue
+		}
+		if len(raw) < 2 || raw[1] != '=' {
+			return nil, fmt.Errorf("sdp: invalid line %d %q", n+1, raw)
+		}
+		line := Line{raw[0], raw[2:]}
+		if len(session.Lines) == 0 && line.Type != 'v' {
+			return nil, fmt.Errorf("sdp: description does not start with v=")
+		}
+
+		if line.Type == 'm' {
+			m, err := parseMedia(line.Value)
+			if err != nil {
+				return nil, err
+			}
+			media = m
+			session.Media = append(session.Media, m)
+		} else if media != nil {
+			media.Lines = append(media.Lines, line)
+		} else {
+			session.Lines = append(session.Lines, line)
+		}
+	}
+	if len(session.Lines) == 0 {
+		return nil, fmt.Errorf("sdp: empty description")
+	}
+	return session, nil
+}
+
+func parseMedia(value string) (*Media, error) {
+	fields := strings.Fields(value)
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("sdp: invalid media %q", value)
+	}
+	m := &Media{Type: fields[0], Proto: fields[2], Formats: fields[3:]}
+
+	port, count, found := strings.Cut(fields[1], "/")
+	var err error
+	if m.Port, err = strconv.Atoi(port); err != nil {
+		return nil, fmt.Errorf("sdp: invalid media port %q", fields[1])
+	}
+	if found {
+		if m.NumPorts, err = strconv.Atoi(count); err != nil {
+			return nil, fmt.Errorf("sdp: invalid media port %q", fields[1])
+		}
+	}
+	return m, nil
+}
+
+// Implement fmt.Stringer interface. Return the description with CRLF line
+// endings.
+func (s *Session) String() string {
+	var b strings.Builder
+	writeLines(&b, s.Lines)
+	for _, m := range s.Media {
+		b.WriteString(m.mediaLine().String())
+		b.WriteString("\r\n")
+		writeLines(&b, m.Lines)
+	}
+	return b.String()
+}
+
+func writeLines(b *strings.Builder, lines []Line) {
+	for _, l := range lines {
+		b.WriteString(l.String())
+		b.WriteString("\r\n")
+	}
+}
+
+func (m *Media) mediaLine() Line {
+	port := strconv.Itoa(m.Port)
+	if m.NumPorts > 0 {
+		port += "/" + strconv.Itoa(m.NumPorts)
+	}
+	fields := append([]string{m.Type, port, m.Proto}, m.Formats...)
+	return Line{'m', strings.Join(fields, " ")}
+}
+
+// MediaByMid returns the media section with the "mid" attribute, or nil.
+func (s *Session) MediaByMid(mid string) *Media {
+	for _, m := range s.Media {
+		if v, ok := m.Attribute("mid"); ok && v == mid {
+			return m
+		}
+	}
+	return nil
+}
+
+// Attributes returns the session level attributes.
+func (s *Session) Attributes() []Attribute {
+	return attributes(s.Lines)
+}
+
+// Attribute returns the value of the first session level attribute "key".
+func (s *Session) Attribute(key string) (string, bool) {
+	return attribute(s.Lines, key)
+}
+
+// SetAttribute replaces the session level attributes "key", or adds one.
+func (s *Session) SetAttribute(key, value string) {
+	s.Lines = setAttribute(s.Lines, key, value)
+}
+
+// RemoveAttribute removes the session level attributes "key".
+func (s *Session) RemoveAttribute(key string) {
+	s.Lines = removeLines(s.Lines, func(l Line) bool { return isAttribute(l, key) })
+}
+
+// Attributes returns the attributes of the media section.
+func (m *Media) Attributes() []Attribute {
+	return attributes(m.Lines)
+}
+
+// Attribute returns the value of the first attribute "key" of the media
+// section.
+func (m *Media) Attribute(key string) (string, bool) {
+	return attribute(m.Lines, key)
+}
+
+// AttributeValues returns the values of the attributes "key" of the media
+// section, e.g. "rtcp-fb".
+func (m *Media) AttributeValues(key string) []string {
+	values := []string{}
+	for _, a := range m.Attributes() {
+		if a.Key == key {
+			values = append(values, a.Value)
+		}
+	}
+	return values
+}
+
+// SetAttribute replaces the attributes "key" of the media section, or adds
+// one.
+func (m *Media) SetAttribute(key, value string) {
+	m.Lines = setAttribute(m.Lines, key, value)
+}
+
+// AddAttribute adds an attribute to the media section.
+func (m *Media) AddAttribute(key, value string) {
+	m.Lines = append(m.Lines, attributeLine(key, value))
+}
+
+// RemoveAttribute removes the attributes "key" of the media section.
+func (m *Media) RemoveAttribute(key string) {
+	m.Lines = removeLines(m.Lines, func(l Line) bool { return isAttribute(l, key) })
+}
+
+func parseAttribute(l Line) Attribute {
+	key, value, _ := strings.Cut(l.Value, ":")
+	return Attribute{key, value}
+}
+
+func attributeLine(key, value string) Line {
+	if value == "" {
+		return Line{'a', key}
+	}
+	return Line{'a', key + ":" + value}
+}
+
+func isAttribute(l Line, key string) bool {
+	return l.Type == 'a' && parseAttribute(l).Key == key
+}
+
+func attributes(lines []Line) []Attribute {
+	ret := []Attribute{}
+	for _, l := range lines {
+		if l.Type == 'a' {
+			ret = append(ret, parseAttribute(l))
+		}
+	}
+	return ret
+}
+
+func attribute(lines []Line, key string) (string, bool) {
+	for _, l := range lines {
+		if isAttribute(l, key) {
+			return parseAttribute(l).Value, true
+		}
+	}
+	return "", false
+}
+
+// Replace the first attribute "key" in place and remove the others, or append
+// it.
+func setAttribute(lines []Line, key, value string) []Line {
+	ret := lines[:0]
+	found := false
+	for _, l := range lines {
+		if !isAttribute(l, key) {
+			ret = append(ret, l)
+		} else if !found {
+			ret = append(ret, attributeLine(key, value))
+			found = true
+		}
+	}
+	if !found {
+		ret = append(ret, attributeLine(key, value))
+	}
+	return ret
+}
+
+func removeLines(lines []Line, remove func(Line) bool) []Line {
+	ret := lines[:0]
+	for _, l := range lines {
+		if !remove(l) {
+			ret = append(ret, l)
+		}
+	}
+	return ret
+}
+
+// Bandwidth modifiers of "b=" lines.
+const (
+	BandwidthAS   = "AS"   // kbit/s, application specific
+	BandwidthTIAS = "TIAS" // bit/s, transport independent, see RFC 3890
+	BandwidthCT   = "CT"   // kbit/s, conference total
+)
+
+// Bandwidth returns the value of the "b=" line of the media section with the
+// modifier.
+func (m *Media) Bandwidth(modifier string) (int, bool) {
+	return bandwidth(m.Lines, modifier)
+}
+
+// SetBandwidth replaces or adds the "b=" line with the modifier, after the
+// "i=" and "c=" lines as RFC 8866 requires.
+func (m *Media) SetBandwidth(modifier string, value int) {
+	m.Lines = setBandwidth(m.Lines, modifier, value, "ic")
+}
+
+// RemoveBandwidth removes the "b=" line with the modifier.
+func (m *Media) RemoveBandwidth(modifier string) {
+	m.Lines = removeLines(m.Lines, func(l Line) bool { return isBandwidth(l, modifier) })
+}
+
+// SetBandwidth replaces or adds the session level "b=" line with the modifier.
+func (s *Session) SetBandwidth(modifier string, value int) {
+	s.Lines = setBandwidth(s.Lines, modifier, value, "vosiuepc")
+}
+
+// RemoveBandwidth removes the session level "b=" line with the modifier.
+func (s *Session) RemoveBandwidth(modifier string) {
+	s.Lines = removeLines(s.Lines, func(l Line) bool { return isBandwidth(l, modifier) })
+}
+
+func isBandwidth(l Line, modifier string) bool {
+	return l.Type == 'b' && strings.HasPrefix(l.Value, modifier+":")
+}
+
+func bandwidth(lines []Line, modifier string) (int, bool) {
+	for _, l := range lines {
+		if isBandwidth(l, modifier) {
+			v, err := strconv.Atoi(strings.TrimPrefix(l.Value, modifier+":"))
+			return v, err == nil
+		}
+	}
+	return 0, false
+}
+
+// Replace the "b=" line in place, or insert it after the last line whose type
+// is in "before".
+func setBandwidth(lines []Line, modifier string, value int, before string) []Line {
+	line := Line{'b', modifier + ":" + strconv.Itoa(value)}
+	for i, l := range lines {
+		if isBandwidth(l, modifier) {
+			lines[i] = line
+			return lines
+		}
+	}
+	at := 0
+	for i, l := range lines {
+		if strings.IndexByte(before, l.Type) >= 0 || l.Type == 'b' {
+			at = i + 1
+		}
+	}
+	lines = append(lines, Line{})
+	copy(lines[at+1:], lines[at:])
+	lines[at] = line
+	return lines
+}
+
+// Direction of a media section.
+type Direction string
+
+// Implement fmt.Stringer interface
+func (t Direction) String() string {
+	return string(t)
+}
+
+const (
+	SendRecv Direction = "sendrecv"
+	SendOnly Direction = "sendonly"
+	RecvOnly Direction = "recvonly"
+	Inactive Direction = "inactive"
+)
+
+func isDirection(l Line) bool {
+	if l.Type != 'a' {
+		return false
+	}
+	switch Direction(l.Value) {
+	case SendRecv, SendOnly, RecvOnly, Inactive:
+		return true
+	}
+	return false
+}
+
+// Direction returns the direction of the media section, or of the session if
+// the section has none. It defaults to sendrecv.
+func (s *Session) Direction(m *Media) Direction {
+	for _, lines := range [][]Line{m.Lines, s.Lines} {
+		for _, l := range lines {
+			if isDirection(l) {
+				return Direction(l.Value)
+			}
+		}
+	}
+	return SendRecv
+}
+
+// SetDirection replaces the direction of the media section, or adds it.
+func (m *Media) SetDirection(d Direction) {
+	for i, l := range m.Lines {
+		if isDirection(l) {
+			m.Lines[i] = Line{'a', string(d)}
+			m.Lines = append(m.Lines[:i+1], removeLines(m.Lines[i+1:], isDirection)...)
+			return
+		}
+	}
+	m.Lines = append(m.Lines, Line{'a', string(d)})
+}
+
+// SetDirection sets the direction of every media section, and removes the
+// session level one.
+func (s *Session) SetDirection(d Direction) {
+	s.Lines = removeLines(s.Lines, isDirection)
+	for _, m := range s.Media {
+		m.SetDirection(d)
+	}
+}
+
+// Candidates returns the candidates of the media section. Invalid candidates
+// are skipped.
+func (m *Media) Candidates() []*Candidate {
+	ret := []*Candidate{}
+	for _, v := range m.AttributeValues("candidate") {
+		if c, err := ParseCandidate(v); err == nil {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+// AddCandidate adds a candidate to the media section.
+func (m *Media) AddCandidate(c Candidate) {
+	m.Lines = append(m.Lines, Line{'a', c.String()})
+}
+
+// FilterCandidates removes the candidates of the media section for which
+// "keep" returns false, including invalid ones.
+func (m *Media) FilterCandidates(keep func(c *Candidate) bool) {
+	m.Lines = removeLines(m.Lines, func(l Line) bool {
+		if !isAttribute(l, "candidate") {
+			return false
+		}
+		c, err := ParseCandidate(l.Value)
+		return err != nil || !keep(c)
+	})
+}
@@ -0,0 +1,181 @@
+package sdp
+
+import (
+	"strings"
+	"testing"
+)
+
+// A trimmed browser offer with VP8 and H264, each with a rtx payload type.
+const testOffer = "v=0\r\n" +
+	"o=- 4611731400430051336 2 IN IP4 127.0.0.1\r\n" +
+	"s=-\r\n" +
+	"t=0 0\r\n" +
+	"a=group:BUNDLE 0 1\r\n" +
+	"m=audio 9 UDP/TLS/RTP/SAVPF 111 0\r\n" +
+	"c=IN IP4 0.0.0.0\r\n" +
+	"a=mid:0\r\n" +
+	"a=sendrecv\r\n" +
+	"a=rtpmap:111 opus/48000/2\r\n" +
+	"a=fmtp:111 minptime=10;useinbandfec=1\r\n" +
+	"m=video 9 UDP/TLS/RTP/SAVPF 96 97 102 103\r\n" +
+	"i=camera\r\n" +
+	"c=IN IP4 0.0.0.0\r\n" +
+	"a=mid:1\r\n" +
+	"a=sendrecv\r\n" +
+	"a=rtpmap:96 VP8/90000\r\n" +
+	"a=rtcp-fb:96 nack\r\n" +
+	"a=rtpmap:97 rtx/90000\r\n" +
+	"a=fmtp:97 apt=96\r\n" +
+	"a=rtpmap:102 H264/90000\r\n" +
+	"a=fmtp:102 level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42001f\r\n" +
+	"a=rtpmap:103 rtx/90000\r\n" +
+	"a=fmtp:103 apt=102\r\n"
+
+func parseTestOffer(t *testing.T) *Session {
+	t.Helper()
+	s, err := Parse(testOffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// Return the lines of a media section, without the "m=" line.
+func mediaLines(m *Media) []string {
+	ret := []string{}
+	for _, l := range m.Lines {
+		ret = append(ret, l.String())
+	}
+	return ret
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"offer", testOffer, testOffer},
+		{"LF line endings", strings.ReplaceAll(testOffer, "\r\n", "\n"), testOffer},
+		{"port count", "v=0\r\nm=video 5004/2 RTP/AVP 96\r\n", "v=0\r\nm=video 5004/2 RTP/AVP 96\r\n"},
+		{"data channel", "v=0\r\nm=application 9 UDP/DTLS/SCTP webrtc-datachannel\r\n", "v=0\r\nm=application 9 UDP/DTLS/SCTP webrtc-datachannel\r\n"},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := s.String(); got != tt.want {
+			t.Errorf("%s: got\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"o=- 1 2 IN IP4 127.0.0.1\r\n",
+		"v=0\r\nbad line\r\n",
+		"v=0\r\nm=video\r\n",
+		"v=0\r\nm=video nine RTP/AVP 96\r\n",
+		"v=0\r\nm=video 9/x RTP/AVP 96\r\n",
+	}
+	for _, in := range tests {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) did not fail", in)
+		}
+	}
+}
+
+func TestSetBandwidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []Line
+		want  []string
+	}{
+		{
+			"after i= and c=",
+			[]Line{{'i', "camera"}, {'c', "IN IP4 0.0.0.0"}, {'a', "mid:1"}},
+			[]string{"i=camera", "c=IN IP4 0.0.0.0", "b=AS:500", "a=mid:1"},
+		},
+		{
+			"after other b=",
+			[]Line{{'c', "IN IP4 0.0.0.0"}, {'b', "TIAS:64000"}, {'a', "mid:1"}},
+			[]string{"c=IN IP4 0.0.0.0", "b=TIAS:64000", "b=AS:500", "a=mid:1"},
+		},
+		{
+			"replaced in place",
+			[]Line{{'c', "IN IP4 0.0.0.0"}, {'b', "AS:100"}, {'b', "TIAS:64000"}},
+			[]string{"c=IN IP4 0.0.0.0", "b=AS:500", "b=TIAS:64000"},
+		},
+		{
+			"first line",
+			[]Line{{'a', "mid:1"}},
+			[]string{"b=AS:500", "a=mid:1"},
+		},
+	}
+	for _, tt := range tests {
+		m := &Media{Lines: tt.lines}
+		m.SetBandwidth(BandwidthAS, 500)
+		if got := mediaLines(m); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		if v, ok := m.Bandwidth(BandwidthAS); !ok || v != 500 {
+			t.Errorf("%s: bandwidth %d %v", tt.name, v, ok)
+		}
+	}
+
+	s := parseTestOffer(t)
+	s.SetBandwidth(BandwidthCT, 2000)
+	if s.Lines[3].String() != "b=CT:2000" || s.Lines[4].String() != "t=0 0" {
+		t.Errorf("session b= line not after s=: %q", s.Lines)
+	}
+}
+
+func TestSetDirection(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []Line
+		want  []string
+	}{
+		{
+			"replaced",
+			[]Line{{'a', "mid:0"}, {'a', "sendrecv"}, {'a', "rtcp-mux"}},
+			[]string{"a=mid:0", "a=recvonly", "a=rtcp-mux"},
+		},
+		{
+			"duplicates removed",
+			[]Line{{'a', "mid:0"}, {'a', "sendonly"}, {'a', "rtcp-mux"}, {'a', "inactive"}, {'a', "sendrecv"}},
+			[]string{"a=mid:0", "a=recvonly", "a=rtcp-mux"},
+		},
+		{
+			"added",
+			[]Line{{'a', "mid:0"}},
+			[]string{"a=mid:0", "a=recvonly"},
+		},
+	}
+	for _, tt := range tests {
+		m := &Media{Lines: tt.lines}
+		m.SetDirection(RecvOnly)
+		if got := mediaLines(m); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	s, err := Parse("v=0\r\na=sendonly\r\nm=audio 9 RTP/AVP 0\r\nm=video 9 RTP/AVP 96\r\na=inactive\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := s.Direction(s.Media[0]); d != SendOnly {
+		t.Errorf("inherited direction %s", d)
+	}
+	if d := s.Direction(s.Media[1]); d != Inactive {
+		t.Errorf("media direction %s", d)
+	}
+	s.SetDirection(RecvOnly)
+	want := "v=0\r\nm=audio 9 RTP/AVP 0\r\na=recvonly\r\nm=video 9 RTP/AVP 96\r\na=recvonly\r\n"
+	if got := s.String(); got != want {
+		t.Errorf("session direction %q, want %q", got, want)
+	}
+}
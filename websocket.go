@@ -1,9 +1,11 @@
 package kurento
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"sync"
 
 	"golang.org/x/net/websocket"
 )
@@ -15,7 +17,13 @@ type Error struct {
 	Data    string
 }
 
-const ConnectionLost = -1
+const (
+	ConnectionLost = -1
+	NotCreated     = -2
+
+	// Returned by the media server for an unknown or released object
+	ObjectNotFound = 40101
+)
 
 // Implements error built-in interface
 func (e *Error) Error() string {
@@ -28,6 +36,17 @@ type Response struct {
 	Id      float64
 	Result  map[string]string // should change if result has no several form
 	Error   *Error
+
+	// raw "value" of the result, for non string values
+	value json.RawMessage
+}
+
+// Decode the result value into "v".
+func (r Response) decodeValue(v interface{}) error {
+	if len(r.value) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.value, v)
 }
 
 type Event struct {
@@ -38,6 +57,7 @@ type Event struct {
 }
 
 type Connection struct {
+	mu        sync.Mutex // protects clientId, clients, events, created and lease
 	clientId  float64
 	eventId   float64
 	clients   map[float64]chan Response
@@ -45,6 +65,8 @@ type Connection struct {
 	ws        *websocket.Conn
 	SessionId string
 	events    map[string]map[string]map[string]eventHandler // eventName -> objectId -> handlerId -> handler.
+	created   map[ObjectID]createRecord                     // objects created through the connection
+	lease     *Lease                                        // lease of created pipelines, if enabled
 	Dead      chan bool
 	IsDead    bool
 }
@@ -60,6 +82,7 @@ func NewConnection(host string) *Connection {
 	connections[host] = c
 
 	c.events = make(map[string]map[string]map[string]eventHandler)
+	c.created = make(map[ObjectID]createRecord)
 	c.clients = make(map[float64]chan Response)
 	c.Dead = make(chan bool, 1)
 
@@ -100,7 +123,16 @@ func (c *Connection) handleResponse() {
 		json.Unmarshal([]byte(message), &r)
 		json.Unmarshal([]byte(message), &ev)
 
-		isResponse := r.Id > 0 && r.Result != nil
+		// Keep the raw value, Result only holds strings
+		var raw struct {
+			Result struct {
+				Value json.RawMessage
+			}
+		}
+		json.Unmarshal([]byte(message), &raw)
+		r.value = raw.Result.Value
+
+		isResponse := r.Id > 0 && (r.Result != nil || r.Error != nil)
 		isEvent := ev.Method == "onEvent"
 
 		if isResponse {
@@ -114,32 +146,53 @@ func (c *Connection) handleResponse() {
 				log.Printf("Response: %v", r)
 			}
 			// if webscocket client exists, send response to the chanel
-			if c.clients[r.Id] != nil {
-				c.clients[r.Id] <- r
-				// chanel is read, we can delete it
-				delete(c.clients, r.Id)
+			c.mu.Lock()
+			client := c.clients[r.Id]
+			delete(c.clients, r.Id)
+			c.mu.Unlock()
+			if client != nil {
+				// chanel is buffered, the client may have given up waiting
+				client <- r
 			} else if debug {
 				log.Println("Dropped message because there is no client ", r.Id)
 				log.Println(r)
 			}
 		} else if isEvent {
 
-			val := ev.Params["value"].(map[string]interface{})
+			var val struct {
+				Params struct {
+					Value struct {
+						Type   string
+						Object string
+						Data   map[string]interface{}
+					}
+				}
+			}
+			json.Unmarshal([]byte(message), &val)
 			if debug {
-				log.Printf("Received event value %v", val)
+				log.Printf("Received event value %v", val.Params.Value)
 			}
 
-			t := val["type"].(string)
-			objectId := val["object"].(string)
+			t := val.Params.Value.Type
+			objectId, err := ParseObjectID(val.Params.Value.Object)
+			if err != nil {
+				if debug {
+					log.Println("Dropped event: ", err)
+				}
+				continue
+			}
 
-			data := val["data"].(map[string]interface{})
+			data := val.Params.Value.Data
 
-			if handlers, ok := c.events[t]; ok {
-				if objHandlers, ok := handlers[objectId]; ok {
-					for _, handler := range objHandlers {
-						handler(data)
-					}
-				}
+			// copy handlers so they can subscribe or unsubscribe
+			var objHandlers []eventHandler
+			c.mu.Lock()
+			for _, handler := range c.events[t][string(objectId)] {
+				objHandlers = append(objHandlers, handler)
+			}
+			c.mu.Unlock()
+			for _, handler := range objHandlers {
+				handler(data)
 			}
 		} else if debug {
 			log.Println("Unsupported message from KMS: ", message)
@@ -151,7 +204,6 @@ func (c *Connection) Request(req map[string]interface{}) <-chan Response {
 	if c.IsDead {
 		errchan := make(chan Response, 1)
 		errresp := Response{
-			Id: req["id"].(float64),
 			Error: &Error{
 				Code:    ConnectionLost,
 				Message: "No connection to Kurento server",
@@ -161,12 +213,17 @@ func (c *Connection) Request(req map[string]interface{}) <-chan Response {
 		return errchan
 	}
 
+	c.mu.Lock()
 	c.clientId++
-	req["id"] = c.clientId
+	id := c.clientId
+	client := make(chan Response, 1)
+	c.clients[id] = client
+	c.mu.Unlock()
+
+	req["id"] = id
 	if c.SessionId != "" {
 		req["sessionId"] = c.SessionId
 	}
-	c.clients[c.clientId] = make(chan Response)
 	if debug {
 		j, _ := json.MarshalIndent(req, "", "    ")
 		log.Println("json", string(j))
@@ -177,7 +234,9 @@ func (c *Connection) Request(req map[string]interface{}) <-chan Response {
 		c.Dead <- true
 		c.IsDead = true
 
-		delete(c.clients, c.clientId)
+		c.mu.Lock()
+		delete(c.clients, id)
+		c.mu.Unlock()
 
 		errchan := make(chan Response, 1)
 		errresp := Response{
@@ -191,10 +250,78 @@ func (c *Connection) Request(req map[string]interface{}) <-chan Response {
 		errchan <- errresp
 		return errchan
 	}
-	return c.clients[c.clientId]
+	return client
+}
+
+// Wait for a response, or for the context to be done. The response error, if
+// any, is returned as error.
+func waitResponse(ctx context.Context, responses <-chan Response) (Response, error) {
+	select {
+	case res := <-responses:
+		if res.Error != nil {
+			return res, res.Error
+		}
+		return res, nil
+	case <-ctx.Done():
+		return Response{}, ctx.Err()
+	}
+}
+
+// How an object was created, that the media server cannot tell.
+type createRecord struct {
+	typeName string
+	from     ObjectID // parent given at creation, the Hub of a HubPort
+	options  map[string]interface{}
+}
+
+// Record the creation of an object. Options are copied, as the caller may
+// reuse its map.
+func (c *Connection) recordCreated(id ObjectID, typeName string, from ObjectID, options map[string]interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.created[id] = createRecord{typeName, from, copyOptions(options)}
+}
+
+func copyOptions(options map[string]interface{}) map[string]interface{} {
+	if options == nil {
+		return nil
+	}
+	ret := make(map[string]interface{}, len(options))
+	for name, value := range options {
+		ret[name] = value
+	}
+	return ret
+}
+
+// Forget a released object, and its children for a pipeline.
+func (c *Connection) recordReleased(id ObjectID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lease != nil {
+		c.lease.Remove(id)
+	}
+	delete(c.created, id)
+	if id.IsPipeline() {
+		for child := range c.created {
+			if child.PipelineID() == id {
+				delete(c.created, child)
+			}
+		}
+	}
+}
+
+// Return how an object was created, if it was created through the connection.
+func (c *Connection) createdRecord(id ObjectID) (createRecord, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.created[id]
+	return r, ok
 }
 
 func (c *Connection) Subscribe(event, objectId, handlerId string, handler eventHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	var oh map[string]map[string]eventHandler
 	var ok bool
 
@@ -213,6 +340,9 @@ func (c *Connection) Subscribe(event, objectId, handlerId string, handler eventH
 }
 
 func (c *Connection) Unsubscribe(event, objectId, handlerId string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	var oh map[string]map[string]eventHandler
 	var he map[string]eventHandler
 	var ok bool